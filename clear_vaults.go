@@ -2,11 +2,15 @@ package main
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -17,6 +21,19 @@ import (
 // Configuration structures (reusing same config.json format as main.go)
 type FileConfig struct {
 	Skyflow SkyflowConfig `json:"skyflow"`
+	Network NetworkConfig `json:"network"`
+}
+
+// NetworkConfig controls how Skyflow is reached (same "network" section as main.go)
+type NetworkConfig struct {
+	ProxyURL                string `json:"proxy_url"`                  // http(s):// or socks5:// proxy (default: HTTPS_PROXY/NO_PROXY environment)
+	CABundle                string `json:"ca_bundle"`                  // PEM file of extra root CAs, added to the system roots
+	ClientCert              string `json:"client_cert"`                // PEM client certificate for mutual TLS
+	ClientKey               string `json:"client_key"`                 // PEM private key for client_cert
+	MinTLSVersion           string `json:"min_tls_version"`            // "1.2" (default) or "1.3"
+	DialTimeoutMs           int    `json:"dial_timeout_ms"`            // TCP connect timeout (default 30000)
+	TLSHandshakeTimeoutMs   int    `json:"tls_handshake_timeout_ms"`   // TLS handshake timeout (default 10000)
+	ResponseHeaderTimeoutMs int    `json:"response_header_timeout_ms"` // Wait for response headers (default 0 = only the request timeout)
 }
 
 type SkyflowConfig struct {
@@ -28,9 +45,18 @@ type SkyflowConfig struct {
 type VaultConfig struct {
 	Name   string `json:"name"`
 	ID     string `json:"id"`
+	Table  string `json:"table"`
 	Column string `json:"column"`
 }
 
+// TableName returns the vault table to clear, falling back to the column name
+func (v VaultConfig) TableName() string {
+	if v.Table != "" {
+		return v.Table
+	}
+	return v.Column
+}
+
 // API response structures
 type FetchResponse struct {
 	Records []struct {
@@ -79,8 +105,13 @@ type TableStats struct {
 	Success       bool
 }
 
-// Create HTTP client with connection pooling
-func createHTTPClient() *http.Client {
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Create HTTP client with connection pooling, proxy, TLS trust and client certificate settings
+func createHTTPClient(nc NetworkConfig) (*http.Client, error) {
 	transport := &http.Transport{
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 100,
@@ -88,12 +119,69 @@ func createHTTPClient() *http.Client {
 		IdleConnTimeout:     90 * time.Second,
 		DisableCompression:  false,
 		DisableKeepAlives:   false,
+		Proxy:               http.ProxyFromEnvironment,
+	}
+
+	dialTimeout := 30 * time.Second
+	if nc.DialTimeoutMs > 0 {
+		dialTimeout = time.Duration(nc.DialTimeoutMs) * time.Millisecond
+	}
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	if nc.TLSHandshakeTimeoutMs > 0 {
+		transport.TLSHandshakeTimeout = time.Duration(nc.TLSHandshakeTimeoutMs) * time.Millisecond
+	}
+	transport.ResponseHeaderTimeout = time.Duration(nc.ResponseHeaderTimeoutMs) * time.Millisecond
+
+	if nc.ProxyURL != "" {
+		proxyURL, err := url.Parse(nc.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q", nc.ProxyURL)
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+			return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if nc.MinTLSVersion != "" {
+		version, ok := tlsVersions[nc.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported min_tls_version %q (use 1.2 or 1.3)", nc.MinTLSVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	if nc.CABundle != "" {
+		pemData, err := os.ReadFile(nc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", nc.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if nc.ClientCert != "" || nc.ClientKey != "" {
+		if nc.ClientCert == "" || nc.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(nc.ClientCert, nc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
+	transport.TLSClientConfig = tlsConfig
 
 	return &http.Client{
 		Transport: transport,
 		Timeout:   REQUEST_TIMEOUT * time.Second,
-	}
+	}, nil
 }
 
 // Fetch records from table with retry logic
@@ -261,7 +349,7 @@ func deleteBatch(client *http.Client, vaultURL, tableName, bearerToken string, b
 
 // Delete all data from a single table
 func deleteTableData(client *http.Client, vaultConfig VaultConfig, vaultURL, bearerToken string) *TableStats {
-	tableName := vaultConfig.Column
+	tableName := vaultConfig.TableName()
 	stats := &TableStats{
 		TableName: tableName,
 		StartTime: time.Now(),
@@ -456,7 +544,7 @@ func deleteAllVaultsParallel(client *http.Client, vaults []VaultConfig, vaultURL
 	fmt.Printf("Progress Notification Interval: Every %d records\n", NOTIFICATION_INTERVAL)
 	fmt.Printf("\nVaults to process (sequentially, with parallel ops within each):\n")
 	for _, v := range vaults {
-		fmt.Printf("  - %s (Vault: %s, ID: %s)\n", v.Column, v.Name, v.ID)
+		fmt.Printf("  - %s (Vault: %s, ID: %s)\n", v.TableName(), v.Name, v.ID)
 	}
 	fmt.Printf("%s\n", strings.Repeat("=", 60))
 
@@ -467,7 +555,7 @@ func deleteAllVaultsParallel(client *http.Client, vaults []VaultConfig, vaultURL
 
 	for _, vault := range vaults {
 		stats := deleteTableData(client, vault, vaultURL, bearerToken)
-		results[vault.Column] = stats
+		results[vault.Name] = stats
 	}
 
 	// Summary
@@ -531,6 +619,11 @@ func main() {
 	bearerToken := flag.String("token", "", "Bearer token for authentication (overrides config)")
 	vaultURL := flag.String("vault-url", "", "Skyflow vault URL (overrides config)")
 	vault := flag.String("vault", "", "Process only specific vault (name, id, dob, ssn)")
+	proxyURL := flag.String("proxy", "", "HTTP(S) or SOCKS5 proxy for Skyflow calls (overrides config network.proxy_url)")
+	caBundle := flag.String("ca-bundle", "", "PEM file of extra root CAs to trust (overrides config network.ca_bundle)")
+	clientCert := flag.String("client-cert", "", "PEM client certificate for mutual TLS (overrides config network.client_cert)")
+	clientKey := flag.String("client-key", "", "PEM private key for -client-cert (overrides config network.client_key)")
+	minTLSVersion := flag.String("min-tls-version", "", "Minimum TLS version: 1.2 or 1.3 (overrides config network.min_tls_version)")
 
 	// Performance tuning flags
 	fetchWorkers := flag.Int("fetch-workers", DEFAULT_MAX_FETCH_WORKERS, "Number of parallel fetch workers")
@@ -618,7 +711,27 @@ func main() {
 	fmt.Printf("%s\n\n", strings.Repeat("=", 60))
 
 	// Create HTTP client
-	client := createHTTPClient()
+	networkConfig := fileConfig.Network
+	if *proxyURL != "" {
+		networkConfig.ProxyURL = *proxyURL
+	}
+	if *caBundle != "" {
+		networkConfig.CABundle = *caBundle
+	}
+	if *clientCert != "" {
+		networkConfig.ClientCert = *clientCert
+	}
+	if *clientKey != "" {
+		networkConfig.ClientKey = *clientKey
+	}
+	if *minTLSVersion != "" {
+		networkConfig.MinTLSVersion = *minTLSVersion
+	}
+	client, err := createHTTPClient(networkConfig)
+	if err != nil {
+		fmt.Printf("❌ Invalid network configuration: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Execute parallel deletion
 	deleteAllVaultsParallel(client, vaults, finalVaultURL, finalBearerToken)
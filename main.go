@@ -3,41 +3,113 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"cmp"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"flag"
 	"fmt"
+	"hash"
 	"io"
 	"log"
-	"math/rand"
+	"log/slog"
+	"maps"
+	"math"
+	"math/bits"
+	mathrand "math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
-
-	_ "github.com/snowflakedb/gosnowflake"
+	"unicode/utf8"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/snowflakedb/gosnowflake"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/term"
+	"golang.org/x/text/unicode/norm"
 )
 
 // FileConfig represents the structure of config.json
 type FileConfig struct {
-	Skyflow     SkyflowConfig       `json:"skyflow"`
-	Snowflake   SnowflakeFileConfig `json:"snowflake"`
-	CSV         CSVConfig           `json:"csv"`
-	Performance PerformanceConfig   `json:"performance"`
+	Skyflow       SkyflowConfig       `json:"skyflow"`
+	Snowflake     SnowflakeFileConfig `json:"snowflake"`
+	CSV           CSVConfig           `json:"csv"`
+	Performance   PerformanceConfig   `json:"performance"`
+	Network       NetworkConfig       `json:"network"`
+	Notifications NotificationsConfig `json:"notifications"`
+}
+
+// NotificationsConfig configures webhook notifications for the run
+type NotificationsConfig struct {
+	Webhooks                []WebhookConfig `json:"webhooks"`
+	ProgressIntervalMinutes int             `json:"progress_interval_minutes"` // Between progress notifications (default 30)
+}
+
+// WebhookConfig is one webhook endpoint
+type WebhookConfig struct {
+	URL            string            `json:"url"`
+	Format         string            `json:"format"`          // "json" (default) or "slack"
+	Events         []string          `json:"events"`          // start, progress, complete, fatal (default: all)
+	Headers        map[string]string `json:"headers"`         // Extra request headers (e.g. Authorization)
+	TimeoutSeconds int               `json:"timeout_seconds"` // Per attempt (default 10)
+	Retries        int               `json:"retries"`         // Retries on network errors, 429 and 5xx (default 3)
+}
+
+// NetworkConfig controls how Skyflow is reached (proxy, TLS trust, client certificates, timeouts)
+type NetworkConfig struct {
+	ProxyURL                string `json:"proxy_url"`                  // http(s):// or socks5:// proxy (default: HTTPS_PROXY/NO_PROXY environment)
+	CABundle                string `json:"ca_bundle"`                  // PEM file of extra root CAs, added to the system roots
+	ClientCert              string `json:"client_cert"`                // PEM client certificate for mutual TLS
+	ClientKey               string `json:"client_key"`                 // PEM private key for client_cert
+	MinTLSVersion           string `json:"min_tls_version"`            // "1.2" (default) or "1.3"
+	DialTimeoutMs           int    `json:"dial_timeout_ms"`            // TCP connect timeout (default 30000)
+	TLSHandshakeTimeoutMs   int    `json:"tls_handshake_timeout_ms"`   // TLS handshake timeout (default 10000)
+	ResponseHeaderTimeoutMs int    `json:"response_header_timeout_ms"` // Wait for response headers (default 0 = only the request timeout)
 }
 
 type SkyflowConfig struct {
-	VaultURL    string        `json:"vault_url"`
-	BearerToken string        `json:"bearer_token"`
-	Vaults      []VaultConfig `json:"vaults"`
+	VaultURL        string        `json:"vault_url"`
+	BearerToken     string        `json:"bearer_token"`
+	CredentialsFile string        `json:"credentials_file"`   // Service account credentials.json (mints and refreshes bearer tokens)
+	ManagementURL   string        `json:"management_url"`     // Management API for vault schemas (default https://manage.skyflowapis.com)
+	CrosswalkKey    string        `json:"crosswalk_hash_key"` // HMAC key for crosswalk value hashes (SKYFLOW_CROSSWALK_HASH_KEY overrides)
+	Vaults          []VaultConfig `json:"vaults"`
 }
 
 type SnowflakeFileConfig struct {
@@ -64,22 +136,100 @@ type PerformanceConfig struct {
 	AppendSuffix   bool `json:"append_suffix"`
 	BaseDelayMs    int  `json:"base_delay_ms"`
 	Upsert         bool `json:"upsert"`
+
+	RequestCompression string `json:"request_compression"` // "", "gzip" or "zstd"
+	CompressionLevel   int    `json:"compression_level"`   // 0 = default for the encoding
+
+	SuffixStrategy string `json:"suffix_strategy"` // "random" (default), "deterministic" or "run-tag"
+	RunTag         string `json:"run_tag"`         // Run ID for deterministic suffixes, or the run-tag suffix
+
+	Dedup       string `json:"dedup"`        // "", "off", "memory" or "disk"
+	DedupPolicy string `json:"dedup_policy"` // "skip", "fail" or "keep-first" (default)
 }
 
 // Configuration (runtime config used by the application)
 type Config struct {
 	VaultURL         string
-	BearerToken      string
+	Auth             *TokenProvider // Supplies bearer tokens (static or minted from service account credentials)
+	ManagementURL    string
+	DryRun           *PayloadSink       // When set, payloads are written here instead of being sent
+	Compressor       *RequestCompressor // When set, request bodies are gzip/zstd compressed
+	Crosswalk        *CrosswalkSink     // When set, skyflow_ids of inserted records are recorded here
+	Rejects          *RejectSink        // Records failing validation rules are written here
+	Dedup            *Deduplicator      // When set, duplicate pairs and token/value conflicts are handled before batching
+	Exporter         *MetricsExporter   // When set, vault metrics are served to Prometheus
 	BatchSize        int
 	MaxConcurrency   int
 	MaxRecords       int
 	AppendSuffix     bool
+	Suffix           *SuffixGenerator // Suffix strategy used when AppendSuffix is set
 	Upsert           bool
 	DataSource       string // "csv" or "snowflake"
 	DataDirectory    string
 	ProgressInterval int
 	BaseRequestDelay time.Duration
 	SnowflakeConfig  SnowflakeConfig
+	VaultAuth        map[string]*TokenProvider // Per-vault credential overrides, keyed by vault name
+}
+
+// ForVault returns the runtime config for one vault: the vault's own endpoint, credentials and
+// performance settings where its entry overrides them, the run-wide values otherwise
+func (c *Config) ForVault(vaultConfig VaultConfig) *Config {
+	if !vaultConfig.HasOverrides() {
+		return c
+	}
+	vc := *c
+	if vaultConfig.VaultURL != "" {
+		vc.VaultURL = strings.TrimRight(vaultConfig.VaultURL, "/")
+	}
+	if auth, ok := c.VaultAuth[vaultConfig.Name]; ok {
+		vc.Auth = auth
+	}
+	if p := vaultConfig.Performance; p != nil {
+		if p.BatchSize > 0 {
+			vc.BatchSize = p.BatchSize
+		}
+		if p.MaxConcurrency > 0 {
+			vc.MaxConcurrency = p.MaxConcurrency
+		}
+		if p.BaseDelayMs != nil {
+			vc.BaseRequestDelay = time.Duration(*p.BaseDelayMs) * time.Millisecond
+		}
+	}
+	return &vc
+}
+
+// endpointLabel names a vault's endpoint in per-vault headers when it differs from the run's
+func endpointLabel(config, vaultRuntime *Config) string {
+	if vaultRuntime.VaultURL == config.VaultURL {
+		return ""
+	}
+	return ", " + vaultRuntime.VaultURL
+}
+
+// EndpointClients hands out one pooled HTTP client per Skyflow endpoint, so vaults in
+// different accounts never share connections
+type EndpointClients struct {
+	maxConns int
+	mu       sync.Mutex
+	clients  map[string]*http.Client
+}
+
+// NewEndpointClients creates an empty client cache (maxConns as for createHTTPClient)
+func NewEndpointClients(maxConns int) *EndpointClients {
+	return &EndpointClients{maxConns: maxConns, clients: make(map[string]*http.Client)}
+}
+
+// For returns the client for an endpoint, creating it on first use
+func (e *EndpointClients) For(endpoint string) *http.Client {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	client, ok := e.clients[endpoint]
+	if !ok {
+		client = createHTTPClient(e.maxConns)
+		e.clients[endpoint] = client
+	}
+	return client
 }
 
 // Snowflake configuration
@@ -103,15 +253,83 @@ type SnowflakeConfig struct {
 
 // Vault configuration
 type VaultConfig struct {
-	Name   string `json:"name"`
-	ID     string `json:"id"`
-	Column string `json:"column"`
+	Name    string          `json:"name"`
+	ID      string          `json:"id"`
+	Table   string          `json:"table"`             // Vault table name (defaults to column for single-column tables)
+	Column  string          `json:"column"`            // Column within the table that receives the value/token (upsert key for multi-column rows)
+	Columns []ColumnMapping `json:"columns,omitempty"` // Multi-column rows: source columns mapped to vault columns
+
+	Transforms []TransformSpec  `json:"transforms,omitempty"` // Value normalization applied before payload creation
+	Validation []ValidationRule `json:"validation,omitempty"` // Records failing a rule are rejected before batching
+
+	// Per-vault overrides for vaults in another Skyflow account or with other limits
+	VaultURL        string                  `json:"vault_url,omitempty"`        // Overrides skyflow.vault_url
+	BearerToken     string                  `json:"bearer_token,omitempty"`     // Overrides the run's bearer token
+	CredentialsFile string                  `json:"credentials_file,omitempty"` // Overrides the run's credentials (wins over bearer_token)
+	Performance     *VaultPerformanceConfig `json:"performance,omitempty"`      // Overrides performance settings
+}
+
+// VaultPerformanceConfig overrides run-wide performance settings for one vault (unset = inherit)
+type VaultPerformanceConfig struct {
+	BatchSize      int  `json:"batch_size,omitempty"`
+	MaxConcurrency int  `json:"max_concurrency,omitempty"`
+	BaseDelayMs    *int `json:"base_delay_ms,omitempty"` // Pointer so 0 can override a non-zero run-wide delay
+}
+
+// HasCredentials reports whether the vault brings its own credentials
+func (v VaultConfig) HasCredentials() bool {
+	return v.BearerToken != "" || v.CredentialsFile != ""
+}
+
+// HasOverrides reports whether the vault changes the run's endpoint, credentials or performance settings
+func (v VaultConfig) HasOverrides() bool {
+	return v.VaultURL != "" || v.HasCredentials() || v.Performance != nil
+}
+
+// ColumnMapping maps one source value/token column pair to a vault column
+type ColumnMapping struct {
+	Source      string `json:"source"`       // Source value column (CSV header or Snowflake column)
+	TokenSource string `json:"token_source"` // Source token column (defaults to source + "_token")
+	Column      string `json:"column"`       // Vault column that receives the value/token
+
+	Transforms []TransformSpec  `json:"transforms,omitempty"` // Extra transforms for this column (after the vault's)
+	Validation []ValidationRule `json:"validation,omitempty"` // Extra rules for this column's value/token
+}
+
+// TokenSourceName returns the source token column, defaulting to <source>_token
+func (c ColumnMapping) TokenSourceName() string {
+	if c.TokenSource != "" {
+		return c.TokenSource
+	}
+	return c.Source + "_token"
+}
+
+// IsMultiColumn reports whether each vault row carries several tokenized columns
+func (v VaultConfig) IsMultiColumn() bool {
+	return len(v.Columns) > 0
+}
+
+// TableName returns the vault table to write to, falling back to the column name
+func (v VaultConfig) TableName() string {
+	if v.Table != "" {
+		return v.Table
+	}
+	return v.Column
 }
 
 // Record for BYOT
+// Single-column vaults use Value/Token; multi-column vaults carry one Field per vault column
 type Record struct {
-	Value string
-	Token string
+	Value  string
+	Token  string
+	Fields []Field `json:",omitempty"`
+}
+
+// Field is one value/token pair destined for a named vault column
+type Field struct {
+	Column string
+	Value  string
+	Token  string
 }
 
 // DataSource interface for reading data from different sources
@@ -121,6 +339,13 @@ type DataSource interface {
 	ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error)
 }
 
+// RecordSampler is implemented by sources that can draw a random sample without loading
+// every record into memory. Returns the sample and the number of records it was drawn from
+// (-1 when the source doesn't know).
+type RecordSampler interface {
+	SampleRecords(vaultConfig VaultConfig, maxRecords, sampleSize int) ([]Record, int, error)
+}
+
 // BatchError captures details about a failed batch for error logging
 type BatchError struct {
 	BatchNumber int       `json:"batch_number"`
@@ -128,25 +353,30 @@ type BatchError struct {
 	Error       string    `json:"error"`
 	StatusCode  int       `json:"status_code,omitempty"`
 	Timestamp   time.Time `json:"timestamp"`
+	Partial     bool      `json:"partial,omitempty"` // The batch succeeded; only these records were rejected by the vault
 }
 
 // Performance metrics with atomic operations for thread safety
 type Metrics struct {
 	VaultName             string
 	TotalRecords          int64
+	SourceRecords         int64 // Records read from the data source
+	PlannedRecords        int64 // Records queued for loading (after validation and dedup)
 	SuccessfulBatches     int64
 	FailedBatches         int64
-	RateLimited429        int64 // Total 429 responses received (including during retries)
-	RetriedSuccesses      int64 // Batches that succeeded after retry
-	ImmediateSuccesses    int64 // Batches that succeeded on first attempt
-	ServerErrors5xx       int64 // Count of 5xx server errors
-	ActiveWorkers         int64 // Currently executing workers
-	ActiveRequests        int64 // HTTP requests in flight
-	TotalRequests         int64 // Total HTTP requests made
-	TotalAPILatency       int64 // Cumulative API response time in nanoseconds
-	MinAPILatency         int64 // Minimum API response time in nanoseconds
-	MaxAPILatency         int64 // Maximum API response time in nanoseconds
-	SnowflakeFetchTime    int64 // nanoseconds
+	RateLimited429        int64        // Total 429 responses received (including during retries)
+	RetriedSuccesses      int64        // Batches that succeeded after retry
+	RecordErrors          int64        // Records the vault rejected individually in successful batches (continueOnError)
+	ImmediateSuccesses    int64        // Batches that succeeded on first attempt
+	ServerErrors5xx       int64        // Count of 5xx server errors
+	ActiveWorkers         int64        // Currently executing workers
+	ActiveRequests        int64        // HTTP requests in flight
+	TotalRequests         int64        // Total HTTP requests made
+	TotalAPILatency       int64        // Cumulative API response time in nanoseconds
+	MinAPILatency         int64        // Minimum API response time in nanoseconds
+	MaxAPILatency         int64        // Maximum API response time in nanoseconds
+	Latency               LatencyStats // API latency histograms (first attempt vs retried, per status class)
+	SnowflakeFetchTime    int64        // nanoseconds
 	RecordCreationTime    int64
 	SuffixGenTime         int64
 	PayloadCreationTime   int64
@@ -154,14 +384,27 @@ type Metrics struct {
 	BaseDelayTime         int64
 	APICallTime           int64
 	RetryDelayTime        int64
+	CompressionTime       int64
+	TransformTime         int64
+	Transforms            *TransformChain  // Value transform counters (nil when the vault has none)
+	Validation            *RecordValidator // Validation counters (nil when the vault has no rules)
+	Dedup                 *VaultDedup      // Duplicate/conflict counts (nil when dedup is off)
+	DedupTime             int64
+	Aborted               bool       // Vault stopped before loading (e.g. dedup conflicts under the fail policy)
+	Error                 string     // Why the vault was not loaded (read, transform, validation or dedup failure)
+	ErrorLogPath          string     // Error log written for failed batches
+	Endpoint              string     // Skyflow vault URL the vault was loaded through
+	Trace                 trace.Span // Vault span batch spans are parented to (nil or no-op when tracing is off)
+	RequestBytes          int64      // Uncompressed request body bytes (all attempts)
+	RequestBytesSent      int64      // Request body bytes on the wire (compressed when enabled)
 	StartTime             time.Time
 	EndTime               time.Time
 	BatchErrors           []BatchError // Thread-safe: only append, protected by mutex
 	BatchErrorsMutex      sync.Mutex
 }
 
-func (m *Metrics) AddRecord() {
-	atomic.AddInt64(&m.TotalRecords, 1)
+func (m *Metrics) AddRecords(n int) {
+	atomic.AddInt64(&m.TotalRecords, int64(n))
 }
 
 func (m *Metrics) AddSuccessfulBatch() {
@@ -191,6 +434,12 @@ func (m *Metrics) AddTime(component string, duration time.Duration) {
 		atomic.AddInt64(&m.APICallTime, nanos)
 	case "retry_delay":
 		atomic.AddInt64(&m.RetryDelayTime, nanos)
+	case "compression":
+		atomic.AddInt64(&m.CompressionTime, nanos)
+	case "transform":
+		atomic.AddInt64(&m.TransformTime, nanos)
+	case "dedup":
+		atomic.AddInt64(&m.DedupTime, nanos)
 	}
 }
 
@@ -213,6 +462,12 @@ func (m *Metrics) GetDuration(component string) time.Duration {
 		nanos = atomic.LoadInt64(&m.APICallTime)
 	case "retry_delay":
 		nanos = atomic.LoadInt64(&m.RetryDelayTime)
+	case "compression":
+		nanos = atomic.LoadInt64(&m.CompressionTime)
+	case "transform":
+		nanos = atomic.LoadInt64(&m.TransformTime)
+	case "dedup":
+		nanos = atomic.LoadInt64(&m.DedupTime)
 	}
 	return time.Duration(nanos)
 }
@@ -229,1414 +484,7024 @@ func (m *Metrics) Throughput() float64 {
 	return 0
 }
 
-// Buffer pool for JSON encoding - reduces GC pressure
-var bufferPool = sync.Pool{
-	New: func() interface{} {
-		return new(bytes.Buffer)
-	},
+// timingComponents lists the AddTime components in the order they are reported
+var timingComponents = []string{
+	"csv_read", "transform", "dedup", "record_creation", "suffix_gen", "payload_creation",
+	"json_serialization", "compression", "base_delay", "api_call", "retry_delay",
 }
 
-// Optimized HTTP client with connection pooling
-// maxConns: maximum connections to allow (0 = use default of 100)
-func createHTTPClient(maxConns int) *http.Client {
-	if maxConns <= 0 {
-		maxConns = 100
-	}
+// apiLatencyBuckets are the upper bounds (seconds) of the exported API latency histogram
+var apiLatencyBuckets = [...]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Latency histograms use HDR-style log-linear buckets at microsecond resolution: values below
+// 2*latencySubBuckets µs are exact, above that every power of two is split into latencySubBuckets
+// linear buckets (~3% relative error). Values above ~71 minutes are clamped.
+const (
+	latencySubBucketBits = 5
+	latencySubBuckets    = 1 << latencySubBucketBits
+	latencyMaxMicros     = 1<<32 - 1
+	latencyBucketCount   = (32-latencySubBucketBits)*latencySubBuckets + latencySubBuckets
+)
 
-	transport := &http.Transport{
-		MaxIdleConns:        maxConns,
-		MaxIdleConnsPerHost: maxConns,
-		MaxConnsPerHost:     maxConns,
-		IdleConnTimeout:     90 * time.Second,
-		DisableCompression:  false,
-		DisableKeepAlives:   false,
-		ForceAttemptHTTP2:   true, // Enable HTTP/2
+// latencyBucket returns the bucket index of a value in microseconds
+func latencyBucket(micros uint64) int {
+	if micros < 2*latencySubBuckets {
+		return int(micros)
 	}
+	shift := bits.Len64(micros) - latencySubBucketBits - 1
+	return shift*latencySubBuckets + int(micros>>shift)
+}
 
-	return &http.Client{
-		Transport: transport,
-		Timeout:   120 * time.Second,
+// latencyBucketUpper returns the highest value (microseconds) that falls into bucket i
+func latencyBucketUpper(i int) uint64 {
+	if i < 2*latencySubBuckets {
+		return uint64(i)
 	}
+	shift := i/latencySubBuckets - 1
+	top := uint64(i%latencySubBuckets + latencySubBuckets)
+	return (top+1)<<shift - 1
 }
 
-// Generate unique suffix (optimized with pre-allocated buffer)
-var suffixChars = []byte("abcdefghijklmnopqrstuvwxyz0123456789")
-
-// Pool of random number generators to avoid global lock contention
-var randPool = sync.Pool{
-	New: func() interface{} {
-		return rand.New(rand.NewSource(time.Now().UnixNano()))
-	},
+// LatencyHistogram is a lock-free latency histogram (the zero value is ready to use)
+type LatencyHistogram struct {
+	counts [latencyBucketCount]int64
+	sum    int64 // nanoseconds
+	max    int64 // nanoseconds
 }
 
-func generateUniqueSuffix() string {
-	// Get per-goroutine random source from pool (avoids global lock)
-	rng := randPool.Get().(*rand.Rand)
-	defer randPool.Put(rng)
-
-	suffix := make([]byte, 16)
-	for i := range suffix {
-		suffix[i] = suffixChars[rng.Intn(len(suffixChars))]
+// Observe records one duration
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	micros := uint64(min(max(d.Microseconds(), 0), latencyMaxMicros))
+	atomic.AddInt64(&h.counts[latencyBucket(micros)], 1)
+	atomic.AddInt64(&h.sum, d.Nanoseconds())
+	for {
+		old := atomic.LoadInt64(&h.max)
+		if old >= d.Nanoseconds() || atomic.CompareAndSwapInt64(&h.max, old, d.Nanoseconds()) {
+			break
+		}
 	}
-	// Avoid fmt.Sprintf - use string concatenation with strconv
-	timestamp := time.Now().Unix()
-	return strconv.FormatInt(timestamp, 10) + "_" + string(suffix)
 }
 
-// ErrorLogDataSource implements DataSource interface for error log JSON files
-type ErrorLogDataSource struct {
-	ErrorLogPath string
-	VaultName    string
-	VaultID      string
-	Column       string
-	Records      []Record
-	ErrorLog     ErrorLogMetadata
+// Snapshot copies the current counts so percentiles are computed from one consistent view
+func (h *LatencyHistogram) Snapshot() *LatencySnapshot {
+	s := &LatencySnapshot{
+		Sum: time.Duration(atomic.LoadInt64(&h.sum)),
+		Max: time.Duration(atomic.LoadInt64(&h.max)),
+	}
+	for i := range h.counts {
+		s.counts[i] = atomic.LoadInt64(&h.counts[i])
+		s.Count += s.counts[i]
+	}
+	return s
 }
 
-// ErrorLogMetadata contains metadata about the error log
-type ErrorLogMetadata struct {
-	Timestamp     time.Time    `json:"timestamp"`
-	TotalErrors   int          `json:"total_errors"`
-	FailedRecords int          `json:"failed_records"`
-	Errors        []BatchError `json:"errors"`
+// LatencySnapshot is a point-in-time copy of a LatencyHistogram
+type LatencySnapshot struct {
+	counts [latencyBucketCount]int64
+	Count  int64
+	Sum    time.Duration
+	Max    time.Duration
 }
 
-// Connect loads and parses the error log file
-func (e *ErrorLogDataSource) Connect() error {
-	file, err := os.Open(e.ErrorLogPath)
-	if err != nil {
-		return fmt.Errorf("failed to open error log file: %w", err)
+// Percentile returns the latency at or below which p percent (0-100) of the requests completed
+func (s *LatencySnapshot) Percentile(p float64) time.Duration {
+	if s.Count == 0 {
+		return 0
 	}
-	defer file.Close()
-
-	var errorLog struct {
-		VaultName     string       `json:"vault_name"`
-		VaultID       string       `json:"vault_id"`
-		Column        string       `json:"column"`
-		Timestamp     time.Time    `json:"timestamp"`
-		TotalErrors   int          `json:"total_errors"`
-		FailedRecords int          `json:"failed_records"`
-		Errors        []BatchError `json:"errors"`
+	rank := max(int64(math.Ceil(p/100*float64(s.Count))), 1)
+	var seen int64
+	for i, c := range s.counts {
+		seen += c
+		if seen >= rank {
+			if i == latencyBucketCount-1 {
+				return s.Max // Clamped values: the top bucket has no meaningful bound
+			}
+			return min(time.Duration(latencyBucketUpper(i))*time.Microsecond, s.Max)
+		}
 	}
+	return s.Max
+}
 
-	if err := json.NewDecoder(file).Decode(&errorLog); err != nil {
-		return fmt.Errorf("failed to parse error log: %w", err)
+// CountAtOrBelow returns the number of requests in buckets that lie entirely at or below d
+func (s *LatencySnapshot) CountAtOrBelow(d time.Duration) int64 {
+	limit := uint64(d.Microseconds())
+	var n int64
+	for i, c := range s.counts {
+		if latencyBucketUpper(i) > limit {
+			break
+		}
+		n += c
 	}
+	return n
+}
 
-	e.VaultName = errorLog.VaultName
-	e.VaultID = errorLog.VaultID
-	e.Column = errorLog.Column
-	e.ErrorLog = ErrorLogMetadata{
-		Timestamp:     errorLog.Timestamp,
-		TotalErrors:   errorLog.TotalErrors,
-		FailedRecords: errorLog.FailedRecords,
-		Errors:        errorLog.Errors,
+// latencyStatusClasses label the per-status-class histograms of LatencyStats
+var latencyStatusClasses = [...]string{"2xx", "429", "4xx", "5xx", "network_error", "other"}
+
+// latencyStatusClass maps an HTTP status (0 = no response) to its latencyStatusClasses index
+func latencyStatusClass(status int) int {
+	switch {
+	case status == 0:
+		return 4
+	case status == 429:
+		return 1
+	case status >= 200 && status < 300:
+		return 0
+	case status >= 400 && status < 500:
+		return 2
+	case status >= 500:
+		return 3
 	}
+	return 5
+}
 
-	// Extract all records from all failed batches
-	for _, batchErr := range errorLog.Errors {
-		e.Records = append(e.Records, batchErr.Records...)
+// LatencyStats holds a vault's API latency histograms: all requests, first attempts vs retries
+// of a batch, and one per response status class
+type LatencyStats struct {
+	All          LatencyHistogram
+	FirstAttempt LatencyHistogram
+	Retried      LatencyHistogram
+	ByStatus     [len(latencyStatusClasses)]LatencyHistogram
+}
+
+// Observe records one HTTP attempt
+func (l *LatencyStats) Observe(d time.Duration, status int, retry bool) {
+	l.All.Observe(d)
+	if retry {
+		l.Retried.Observe(d)
+	} else {
+		l.FirstAttempt.Observe(d)
 	}
+	l.ByStatus[latencyStatusClass(status)].Observe(d)
+}
 
-	return nil
+// formatLatencyMs formats a duration in milliseconds for the [LIVE] line and summary tables
+func formatLatencyMs(d time.Duration) string {
+	ms := float64(d) / float64(time.Millisecond)
+	if ms < 10 {
+		return strconv.FormatFloat(ms, 'f', 1, 64)
+	}
+	return strconv.FormatFloat(ms, 'f', 0, 64)
 }
 
-// Close is a no-op for error log files
-func (e *ErrorLogDataSource) Close() error {
-	return nil
+// exportedVault is a vault registered with the metrics exporter
+type exportedVault struct {
+	metrics *Metrics
+	workers int
+	running bool
 }
 
-// ReadRecords returns the records loaded from the error log
-func (e *ErrorLogDataSource) ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error) {
-	if maxRecords > 0 && maxRecords < len(e.Records) {
-		return e.Records[:maxRecords], nil
-	}
-	return e.Records, nil
+// MetricsExporter serves the per-vault Metrics of the running load in the Prometheus text format
+type MetricsExporter struct {
+	Addr    string // Listen address (resolved to the bound address by Start)
+	started time.Time
+	server  *http.Server
+	mu      sync.Mutex
+	vaults  []exportedVault
 }
 
-// CSVDataSource implements DataSource interface for local CSV files
-type CSVDataSource struct {
-	DataDirectory string
+// NewMetricsExporter creates an exporter for the given listen address (e.g. ":9102")
+func NewMetricsExporter(addr string) *MetricsExporter {
+	return &MetricsExporter{Addr: addr, started: time.Now()}
 }
 
-// Connect validates CSV data directory exists
-func (c *CSVDataSource) Connect() error {
-	if _, err := os.Stat(c.DataDirectory); os.IsNotExist(err) {
-		return fmt.Errorf("data directory not found: %s", c.DataDirectory)
+// Start binds the listen address and serves /metrics in the background
+func (e *MetricsExporter) Start() error {
+	listener, err := net.Listen("tcp", e.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", e.Addr, err)
 	}
-	return nil
-}
+	e.Addr = listener.Addr().String()
 
-// Close is a no-op for CSV files
-func (c *CSVDataSource) Close() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(e.render())
+	})
+	e.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go e.server.Serve(listener)
 	return nil
 }
 
-// ReadRecords reads records from CSV files
-func (c *CSVDataSource) ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error) {
-	// Construct file paths based on vault type
-	dataFilePath := fmt.Sprintf("%s/%s_data.csv", c.DataDirectory, vaultConfig.Column)
-	tokenFilePath := fmt.Sprintf("%s/%s_tokens.csv", c.DataDirectory, vaultConfig.Column)
+// Close stops the listener
+func (e *MetricsExporter) Close() {
+	if e != nil && e.server != nil {
+		e.server.Close()
+	}
+}
 
-	// Open data file
-	dataFile, err := os.Open(dataFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open data file %s: %w", dataFilePath, err)
+// Register exposes a vault's metrics from now on (nil-safe: no-op when metrics are disabled)
+func (e *MetricsExporter) Register(m *Metrics, workers int) {
+	if e == nil {
+		return
 	}
-	defer dataFile.Close()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.vaults = append(e.vaults, exportedVault{metrics: m, workers: workers, running: true})
+}
 
-	// Open token file
-	tokenFile, err := os.Open(tokenFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open token file %s: %w", tokenFilePath, err)
+// Finish marks a registered vault as done (nil-safe)
+func (e *MetricsExporter) Finish(m *Metrics) {
+	if e == nil {
+		return
 	}
-	defer tokenFile.Close()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.vaults {
+		if e.vaults[i].metrics == m {
+			e.vaults[i].running = false
+		}
+	}
+}
 
-	dataReader := csv.NewReader(dataFile)
-	tokenReader := csv.NewReader(tokenFile)
+// promLabel escapes a Prometheus label value
+var promLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
 
-	// Optimize CSV reading
-	dataReader.ReuseRecord = true
-	tokenReader.ReuseRecord = true
+// render writes every metric family for the registered vaults
+func (e *MetricsExporter) render() []byte {
+	e.mu.Lock()
+	vaults := slices.Clone(e.vaults)
+	e.mu.Unlock()
 
-	// Read headers
-	dataHeaders, err := dataReader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read data headers: %w", err)
+	var b bytes.Buffer
+	header := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP skyflow_loader_%s %s\n# TYPE skyflow_loader_%s %s\n", name, help, name, kind)
 	}
-	dataHeaders = append([]string(nil), dataHeaders...) // Copy headers
-
-	tokenHeaders, err := tokenReader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read token headers: %w", err)
+	sample := func(name string, v exportedVault, labels string, value float64) {
+		fmt.Fprintf(&b, "skyflow_loader_%s{vault=\"%s\"%s} %s\n",
+			name, promLabel.Replace(v.metrics.VaultName), labels, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+	family := func(name, kind, help string, value func(m *Metrics) int64) {
+		header(name, kind, help)
+		for _, v := range vaults {
+			sample(name, v, "", float64(value(v.metrics)))
+		}
 	}
-	tokenHeaders = append([]string(nil), tokenHeaders...) // Copy headers
-
-	// Find column indices
-	dataColIdx := -1
-	tokenColIdx := -1
 
-	dataColName := getDataColumnName(vaultConfig.Column)
-	tokenColName := getTokenColumnName(vaultConfig.Column)
+	header("run_start_time_seconds", "gauge", "Unix time the loader started.")
+	fmt.Fprintf(&b, "skyflow_loader_run_start_time_seconds %d\n", e.started.Unix())
 
-	for i, h := range dataHeaders {
-		if h == dataColName {
-			dataColIdx = i
-			break
+	header("vault_running", "gauge", "1 while the vault is being loaded, 0 once it has finished.")
+	for _, v := range vaults {
+		running := 0.0
+		if v.running {
+			running = 1
 		}
+		sample("vault_running", v, "", running)
 	}
+	family("records_planned", "gauge", "Records queued for loading after validation and deduplication.", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.PlannedRecords)
+	})
+	family("records_loaded_total", "counter", "Records in successfully loaded batches.", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.TotalRecords)
+	})
 
-	for i, h := range tokenHeaders {
-		if h == tokenColName {
-			tokenColIdx = i
-			break
-		}
+	header("batches_total", "counter", "Finished batches by outcome.")
+	for _, v := range vaults {
+		sample("batches_total", v, `,outcome="success_first_attempt"`, float64(atomic.LoadInt64(&v.metrics.ImmediateSuccesses)))
+		sample("batches_total", v, `,outcome="success_after_retry"`, float64(atomic.LoadInt64(&v.metrics.RetriedSuccesses)))
+		sample("batches_total", v, `,outcome="failed"`, float64(atomic.LoadInt64(&v.metrics.FailedBatches)))
 	}
 
-	if dataColIdx == -1 || tokenColIdx == -1 {
-		return nil, fmt.Errorf("column not found: data=%s token=%s", dataColName, tokenColName)
+	family("http_requests_total", "counter", "HTTP requests sent to the vault API (all attempts).", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.TotalRequests)
+	})
+	family("rate_limited_total", "counter", "HTTP 429 responses.", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.RateLimited429)
+	})
+	family("server_errors_total", "counter", "HTTP 5xx responses.", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.ServerErrors5xx)
+	})
+	family("http_requests_in_flight", "gauge", "HTTP requests currently in flight.", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.ActiveRequests)
+	})
+	family("active_workers", "gauge", "Workers currently sending a batch.", func(m *Metrics) int64 {
+		return atomic.LoadInt64(&m.ActiveWorkers)
+	})
+	header("workers", "gauge", "Configured workers (concurrency) for the vault.")
+	for _, v := range vaults {
+		sample("workers", v, "", float64(v.workers))
 	}
 
-	// Pre-allocate slice with capacity
-	capacity := maxRecords
-	if capacity <= 0 {
-		capacity = 10000
+	header("request_bytes_total", "counter", "Request body bytes before and after compression (all attempts).")
+	for _, v := range vaults {
+		sample("request_bytes_total", v, `,encoding="uncompressed"`, float64(atomic.LoadInt64(&v.metrics.RequestBytes)))
+		sample("request_bytes_total", v, `,encoding="wire"`, float64(atomic.LoadInt64(&v.metrics.RequestBytesSent)))
 	}
-	records := make([]Record, 0, capacity)
-	recordCount := 0
 
-	for {
-		if maxRecords > 0 && recordCount >= maxRecords {
-			break
+	header("api_latency_seconds", "histogram", "Vault API response time per HTTP request by response status class.")
+	for _, v := range vaults {
+		for class, name := range latencyStatusClasses {
+			snapshot := v.metrics.Latency.ByStatus[class].Snapshot()
+			if snapshot.Count == 0 {
+				continue
+			}
+			labels := `,status_class="` + name + `"`
+			for _, le := range apiLatencyBuckets {
+				bound := time.Duration(le * float64(time.Second))
+				sample("api_latency_seconds_bucket", v, labels+`,le="`+strconv.FormatFloat(le, 'g', -1, 64)+`"`,
+					float64(snapshot.CountAtOrBelow(bound)))
+			}
+			sample("api_latency_seconds_bucket", v, labels+`,le="+Inf"`, float64(snapshot.Count))
+			sample("api_latency_seconds_sum", v, labels, snapshot.Sum.Seconds())
+			sample("api_latency_seconds_count", v, labels, float64(snapshot.Count))
 		}
+	}
 
-		dataRow, err := dataReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("error reading data row: %w", err)
+	header("stage_seconds_total", "counter", "Time spent per pipeline stage, summed over workers.")
+	for _, v := range vaults {
+		for _, component := range timingComponents {
+			sample("stage_seconds_total", v, `,stage="`+component+`"`, v.metrics.GetDuration(component).Seconds())
 		}
+	}
+	return b.Bytes()
+}
 
-		tokenRow, err := tokenReader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, fmt.Errorf("error reading token row: %w", err)
-		}
+// Tracing: optional OpenTelemetry spans for the run, each vault, each batch and each HTTP attempt,
+// exported with the OpenTelemetry SDK over OTLP/HTTP (-trace-otlp) and/or to a JSON Lines file (-trace-file)
 
-		if dataColIdx < len(dataRow) && tokenColIdx < len(tokenRow) {
-			value := dataRow[dataColIdx]
-			token := tokenRow[tokenColIdx]
+// tracer is nil unless tracing is enabled; every Tracer method is nil-safe and hands out no-op
+// spans when tracing is off
+var tracer *Tracer
 
-			if value != "" && token != "" {
-				// Copy strings to avoid retaining CSV reader's internal buffer
-				records = append(records, Record{
-					Value: strings.Clone(value),
-					Token: strings.Clone(token),
-				})
-				recordCount++
-			}
+// noopSpan stands in for spans that are not traced (a non-recording span ignores every call)
+var noopSpan = trace.SpanFromContext(context.Background())
+
+const (
+	traceExportBatch     = 512              // Queued spans that trigger an export
+	traceMaxQueue        = 65536            // Spans beyond this are dropped while an export is slow
+	traceFlushInterval   = 5 * time.Second  // Export queued spans at least this often
+	traceShutdownTimeout = 15 * time.Second // Time allowed to export the remaining spans at exit
+)
+
+// Tracer owns the SDK tracer provider and the run span every vault span is parented to
+type Tracer struct {
+	Endpoint   string  // OTLP/HTTP traces URL (empty: no collector)
+	FilePath   string  // JSON Lines file (empty: no file)
+	SampleRate float64 // Fraction of batches traced (run and vault spans are always kept)
+	Exported   int64
+	Failed     int64 // Spans in exports the collector or file rejected
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	root       trace.Span
+	warnOnce   sync.Once
+	closeOnce  sync.Once
+}
+
+// NewTracer validates the export targets and starts the SDK's background batch exporter
+func NewTracer(endpoint, filePath string, sampleRate float64) (*Tracer, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("sample rate must be between 0 and 1, got %g", sampleRate)
+	}
+	t := &Tracer{FilePath: filePath, SampleRate: sampleRate, root: noopSpan}
+	exporter := &traceExporter{tracer: t}
+	if endpoint != "" {
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid OTLP endpoint %q", endpoint)
+		}
+		if !strings.HasSuffix(u.Path, "/v1/traces") {
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/traces"
+		}
+		t.Endpoint = u.String()
+		otlp, err := otlptracehttp.New(context.Background(),
+			otlptracehttp.WithEndpointURL(t.Endpoint), otlptracehttp.WithTimeout(10*time.Second))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		exporter.targets = append(exporter.targets, otlp)
+	}
+	if filePath != "" {
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace file: %w", err)
 		}
+		stdout, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to create trace file exporter: %w", err)
+		}
+		exporter.targets = append(exporter.targets, stdout)
+		exporter.file = file
 	}
 
-	return records, nil
+	hostname, _ := os.Hostname()
+	t.provider = sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "skyflow-byot-loader"),
+			attribute.String("host.name", hostname),
+			attribute.Int("process.pid", os.Getpid()),
+			attribute.String("os.type", runtime.GOOS),
+		)),
+		// Spans follow their parent's decision, so an unsampled batch drops all of its children
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+		sdktrace.WithBatcher(exporter,
+			sdktrace.WithMaxExportBatchSize(traceExportBatch),
+			sdktrace.WithMaxQueueSize(traceMaxQueue),
+			sdktrace.WithBatchTimeout(traceFlushInterval)),
+	)
+	t.tracer = t.provider.Tracer("skyflow-byot-loader")
+	return t, nil
 }
 
-// SnowflakeDataSource implements DataSource interface for Snowflake
-type SnowflakeDataSource struct {
-	Config SnowflakeConfig
-	DB     *sql.DB
+// StartRun opens the root span every vault span is parented to
+func (t *Tracer) StartRun(mode string, attrs ...attribute.KeyValue) trace.Span {
+	if t == nil {
+		return noopSpan
+	}
+	_, t.root = t.tracer.Start(context.Background(), "loader "+mode,
+		trace.WithAttributes(append([]attribute.KeyValue{attribute.String("loader.mode", mode)}, attrs...)...))
+	return t.root
 }
 
-// Connect establishes connection to Snowflake
-func (s *SnowflakeDataSource) Connect() error {
-	// Build DSN (Data Source Name) with URL encoding for special characters
-	// Format: user:password@account/database/schema?warehouse=wh&role=role&authenticator=type
-	dsn := fmt.Sprintf("%s:%s@%s/%s/%s?warehouse=%s&role=%s",
-		url.QueryEscape(s.Config.User),
-		url.QueryEscape(s.Config.Password),
-		s.Config.Account,
-		s.Config.Database,
-		s.Config.Schema,
-		url.QueryEscape(s.Config.Warehouse),
-		url.QueryEscape(s.Config.Role),
-	)
+// Start opens a span under parent (the run span when parent is nil)
+func (t *Tracer) Start(parent trace.Span, name string, attrs ...attribute.KeyValue) trace.Span {
+	if t == nil {
+		return noopSpan
+	}
+	if parent == nil {
+		parent = t.root
+	}
+	_, span := t.tracer.Start(trace.ContextWithSpan(context.Background(), parent), name, trace.WithAttributes(attrs...))
+	return span
+}
 
-	// Add authenticator if specified (e.g., "programmatic_access_token")
-	if s.Config.Authenticator != "" {
-		dsn += fmt.Sprintf("&authenticator=%s", url.QueryEscape(s.Config.Authenticator))
+// StartSampled is Start for per-batch spans. Batches outside the sample rate get an unsampled
+// span: it records nothing, and its children (stages, HTTP attempts) are dropped with it.
+func (t *Tracer) StartSampled(parent trace.Span, name string, attrs ...attribute.KeyValue) trace.Span {
+	if t == nil {
+		return noopSpan
 	}
+	if parent == nil {
+		parent = t.root
+	}
+	ctx := trace.ContextWithSpan(context.Background(), parent)
+	if t.SampleRate < 1 && mathrand.Float64() >= t.SampleRate {
+		sc := parent.SpanContext()
+		ctx = trace.ContextWithSpanContext(ctx, sc.WithTraceFlags(sc.TraceFlags().WithSampled(false)))
+	}
+	_, span := t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return span
+}
 
-	db, err := sql.Open("snowflake", dsn)
-	if err != nil {
-		return fmt.Errorf("failed to open Snowflake connection: %w", err)
+// Record adds an already finished child span under parent that ran from start until now
+func (t *Tracer) Record(parent trace.Span, name string, start time.Time, attrs ...attribute.KeyValue) {
+	if t == nil || parent == nil || !parent.IsRecording() {
+		return
 	}
+	_, span := t.tracer.Start(trace.ContextWithSpan(context.Background(), parent), name,
+		trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	span.End()
+}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Hour)
+// Transport wraps base so every request made inside a span gets an HTTP client span and the
+// span's W3C traceparent header; requests outside a span (token minting) are left alone
+func (t *Tracer) Transport(base http.RoundTripper) http.RoundTripper {
+	if t == nil {
+		return base
+	}
+	return otelhttp.NewTransport(base,
+		otelhttp.WithTracerProvider(t.provider),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.Method }),
+		otelhttp.WithFilter(func(r *http.Request) bool { return trace.SpanContextFromContext(r.Context()).IsValid() }))
+}
 
-	// Test connection
-	if err := db.Ping(); err != nil {
-		db.Close()
-		return fmt.Errorf("failed to ping Snowflake: %w", err)
+// exportFailed counts spans that were not exported and warns once (the load itself continues)
+func (t *Tracer) exportFailed(spans int, err error) {
+	atomic.AddInt64(&t.Failed, int64(spans))
+	t.warnOnce.Do(func() {
+		logger.Warn(fmt.Sprintf("Trace export failed, continuing without it: %v", err), "component", "tracing", "error", err)
+	})
+}
+
+// Shutdown ends the run span (as an error when reason is set), exports the remaining spans and
+// closes the trace file; safe to call more than once
+func (t *Tracer) Shutdown(reason string) {
+	if t == nil {
+		return
 	}
+	t.closeOnce.Do(func() {
+		if reason != "" {
+			setSpanError(t.root, reason)
+		}
+		t.root.End()
+		ctx, cancel := context.WithTimeout(context.Background(), traceShutdownTimeout)
+		defer cancel()
+		if err := t.provider.Shutdown(ctx); err != nil {
+			t.exportFailed(0, err)
+		}
 
-	s.DB = db
-	fmt.Println("✅ Successfully connected to Snowflake")
-	return nil
+		var targets []string
+		if t.Endpoint != "" {
+			targets = append(targets, t.Endpoint)
+		}
+		if t.FilePath != "" {
+			targets = append(targets, t.FilePath)
+		}
+		fmt.Printf("🔭 Traces: %s spans exported to %s (%s failed)\n",
+			formatNumber(int(atomic.LoadInt64(&t.Exported))), strings.Join(targets, " and "),
+			formatNumber(int(atomic.LoadInt64(&t.Failed))))
+	})
 }
 
-// Close closes the Snowflake connection
-func (s *SnowflakeDataSource) Close() error {
-	if s.DB != nil {
-		return s.DB.Close()
+// traceExporter sends each batch of ended spans to every target, counting what was exported.
+// Failures are counted and warned about here rather than returned, so the SDK does not log
+// every failed export.
+type traceExporter struct {
+	tracer  *Tracer
+	targets []sdktrace.SpanExporter
+	file    *os.File
+}
+
+func (e *traceExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	failed := false
+	for _, target := range e.targets {
+		if err := target.ExportSpans(ctx, spans); err != nil {
+			e.tracer.exportFailed(len(spans), err)
+			failed = true
+		}
+	}
+	if !failed {
+		atomic.AddInt64(&e.tracer.Exported, int64(len(spans)))
 	}
 	return nil
 }
 
-// buildSimpleQuery creates query for simple mode (single table with value + token columns)
-func (s *SnowflakeDataSource) buildSimpleQuery(vaultConfig VaultConfig) string {
-	// Build fully qualified table name
-	tableName := s.Config.SimpleTable
-	if tableName == "" {
-		// Default to ELEVANCE.PUBLIC.PATIENTS for backwards compatibility
-		tableName = "ELEVANCE.PUBLIC.PATIENTS"
+func (e *traceExporter) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, target := range e.targets {
+		errs = append(errs, target.Shutdown(ctx))
 	}
-	// If table doesn't contain dots, prepend database.schema
-	if !strings.Contains(tableName, ".") {
-		tableName = fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, tableName)
+	if e.file != nil {
+		errs = append(errs, e.file.Close())
 	}
+	return errors.Join(errs...)
+}
 
-	var query string
-	switch vaultConfig.Column {
-	case "name":
-		query = fmt.Sprintf(`SELECT DISTINCT UPPER(full_name) AS full_name, full_name_token
-				FROM %s
-				WHERE full_name IS NOT NULL AND full_name_token IS NOT NULL`, tableName)
-	case "id":
-		query = fmt.Sprintf(`SELECT DISTINCT TO_VARCHAR(id) AS id, id_token
-				FROM %s
-				WHERE id IS NOT NULL AND id_token IS NOT NULL`, tableName)
-	case "dob":
-		query = fmt.Sprintf(`SELECT DISTINCT TO_VARCHAR(dob) AS dob, dob_token
-				FROM %s
-				WHERE dob IS NOT NULL AND dob_token IS NOT NULL`, tableName)
-	case "ssn":
-		query = fmt.Sprintf(`SELECT DISTINCT TO_VARCHAR(ssn) AS ssn, ssn_token
-				FROM %s
-				WHERE ssn IS NOT NULL AND ssn_token IS NOT NULL`, tableName)
-	}
-	return query
+// setSpanError marks span as failed; the message is passed through the PII redaction filter
+func setSpanError(span trace.Span, message string) {
+	span.SetStatus(codes.Error, redactPII(message))
 }
 
-// buildGenericQuery creates query with UNIONs from configurable CLM and MBR tables
-func (s *SnowflakeDataSource) buildGenericQuery(vaultConfig VaultConfig) string {
-	// Build fully qualified table names
-	clmTable := fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, s.Config.CLMTable)
-	mbrTable := fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, s.Config.MBRTable)
+// Buffer pool for JSON encoding - reduces GC pressure
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
 
-	var query string
-	switch vaultConfig.Column {
-	case "name":
-		// First name, Last name, Middle initial from both CLM and MBR tables
-		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
-		query = fmt.Sprintf(`SELECT NAME, NAME_TOKEN
-FROM (
-	SELECT SRC_MBR_FRST_NM AS NAME, SRC_MBR_FRST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT FRST_NM AS NAME, FRST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SRC_MBR_LAST_NM AS NAME, SRC_MBR_LAST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT LAST_NM AS NAME, LAST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SRC_MBR_MID_INIT_NM AS NAME, SRC_MBR_MID_INIT_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT MID_INIT_NM AS NAME, MID_INIT_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
-) DT
-GROUP BY 1, 2`, clmTable, mbrTable, clmTable, mbrTable, clmTable, mbrTable)
-	case "id":
-		// Multiple ID types from both CLM and MBR tables
-		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
-		query = fmt.Sprintf(`SELECT ID, ID_TOKEN
-FROM (
-	SELECT SRC_ENRLMNT_ID AS ID, SRC_ENRLMNT_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SRC_ENRLMNT_ID AS ID, SRC_ENRLMNT_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SRC_HC_ID AS ID, SRC_HC_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT HC_ID AS ID, HC_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SRC_SBSCRBR_ID AS ID, SRC_SBSCRBR_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SBSCRBR_ID AS ID, SBSCRBR_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
-) DT
-GROUP BY 1, 2`, clmTable, mbrTable, clmTable, mbrTable, clmTable, mbrTable)
-	case "dob":
-		// Birth date from both CLM and MBR tables
-		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
-		query = fmt.Sprintf(`SELECT BRTH_DT, BRTH_DT_TOKEN
-FROM (
-	SELECT SRC_MBR_BRTH_DT AS BRTH_DT, SRC_MBR_BRTH_DT_TOKEN AS BRTH_DT_TOKEN FROM %s
-	UNION
-	SELECT BRTH_DT, BRTH_DT_TOKEN FROM %s
-) DT
-GROUP BY 1, 2`, clmTable, mbrTable)
-	case "ssn":
-		// SSN from both CLM and MBR tables
-		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
-		query = fmt.Sprintf(`SELECT SSN, SSN_TOKEN
-FROM (
-	SELECT SRC_MBR_SSN AS SSN, SRC_MBR_SSN_TOKEN AS SSN_TOKEN FROM %s GROUP BY 1, 2
-	UNION
-	SELECT SSN, SSN_TOKEN FROM %s GROUP BY 1, 2
-) DT
-GROUP BY 1, 2`, clmTable, mbrTable)
-	}
-	return query
+// NetworkSettings is the resolved NetworkConfig applied to every Skyflow HTTP transport
+type NetworkSettings struct {
+	ProxyURL              *url.URL // nil: proxy from the environment
+	TLSConfig             *tls.Config
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
 }
 
-// buildUnionQuery creates query for D01_SKYFLOW_POC with UNIONs and UDF detokenization
-func (s *SnowflakeDataSource) buildUnionQuery(vaultConfig VaultConfig) string {
-	var query string
-	switch vaultConfig.Column {
-	case "name":
-		// First name, Last name, Middle initial from both CLM and MBR tables
-		// Uses UDF detokenization on-the-fly
-		query = `SELECT NAME, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_MBR_NAME_DETOK(NAME) AS name_token
-FROM (
-	SELECT SRC_MBR_FRST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT FRST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-	UNION
-	SELECT SRC_MBR_LAST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT LAST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-	UNION
-	SELECT SRC_MBR_MID_INIT_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT MID_INIT_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-) DT
-GROUP BY 1, 2`
-	case "id":
-		// Multiple ID types from both CLM and MBR tables
-		// Uses UDF detokenization on-the-fly
-		query = `SELECT ID, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_MBR_IDENTIFIERS_DETOK(ID) AS id_token
-FROM (
-	SELECT SRC_ENRLMNT_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT SRC_ENRLMNT_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-	UNION
-	SELECT SRC_HC_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT HC_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-	UNION
-	SELECT SRC_SBSCRBR_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT SBSCRBR_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-) DT
-GROUP BY 1, 2`
-	case "dob":
-		// Birth date from both CLM and MBR tables
-		// Uses UDF detokenization on-the-fly
-		query = `SELECT BRTH_DT, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_BIRTHDATE_DETOK(BRTH_DT) AS dob_token
-FROM (
-	SELECT SRC_MBR_BRTH_DT AS BRTH_DT FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM
-	UNION
-	SELECT BRTH_DT FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR
-) DT
-GROUP BY 1, 2`
-	case "ssn":
-		// SSN from both CLM and MBR tables
-		// Uses UDF detokenization on-the-fly
-		query = `SELECT SSN, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_SSN_DETOK(SSN) AS ssn_token
-FROM (
-	SELECT SRC_MBR_SSN AS SSN FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
-	UNION
-	SELECT SSN FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
-) DT
-GROUP BY 1, 2`
-	}
-	return query
+// networkSettings is set once at startup (before any client is created) and read by createHTTPClient
+var networkSettings = &NetworkSettings{
+	TLSConfig:           &tls.Config{MinVersion: tls.VersionTLS12},
+	DialTimeout:         30 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
 }
 
-// ReadRecords reads records from Snowflake using cursor-based fetching
-// The query executes ONCE on Snowflake, then rows are fetched incrementally via cursor
-// The Snowflake driver automatically batches network fetches based on fetch_size
-func (s *SnowflakeDataSource) ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error) {
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// NewNetworkSettings validates the network config and loads its CA bundle and client certificate
+func NewNetworkSettings(nc NetworkConfig) (*NetworkSettings, error) {
+	settings := &NetworkSettings{
+		TLSConfig:             &tls.Config{MinVersion: tls.VersionTLS12},
+		DialTimeout:           30 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: time.Duration(nc.ResponseHeaderTimeoutMs) * time.Millisecond,
+	}
+	if nc.DialTimeoutMs > 0 {
+		settings.DialTimeout = time.Duration(nc.DialTimeoutMs) * time.Millisecond
+	}
+	if nc.TLSHandshakeTimeoutMs > 0 {
+		settings.TLSHandshakeTimeout = time.Duration(nc.TLSHandshakeTimeoutMs) * time.Millisecond
+	}
+
+	if nc.ProxyURL != "" {
+		proxyURL, err := url.Parse(nc.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q", nc.ProxyURL)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", proxyURL.Scheme)
+		}
+		settings.ProxyURL = proxyURL
+	}
+
+	if nc.MinTLSVersion != "" {
+		version, ok := tlsVersions[nc.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported min_tls_version %q (use 1.2 or 1.3)", nc.MinTLSVersion)
+		}
+		settings.TLSConfig.MinVersion = version
+	}
+
+	if nc.CABundle != "" {
+		pemData, err := os.ReadFile(nc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", nc.CABundle)
+		}
+		settings.TLSConfig.RootCAs = pool
+	}
+
+	if nc.ClientCert != "" || nc.ClientKey != "" {
+		if nc.ClientCert == "" || nc.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(nc.ClientCert, nc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		settings.TLSConfig.Certificates = []tls.Certificate{cert}
+	}
+	return settings, nil
+}
+
+// Apply sets the proxy, TLS and timeout options on a transport
+func (n *NetworkSettings) Apply(transport *http.Transport) {
+	transport.Proxy = http.ProxyFromEnvironment
+	if n.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(n.ProxyURL)
+	}
+	transport.TLSClientConfig = n.TLSConfig.Clone()
+	transport.DialContext = (&net.Dialer{Timeout: n.DialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = n.TLSHandshakeTimeout
+	transport.ResponseHeaderTimeout = n.ResponseHeaderTimeout
+}
+
+// Describe summarizes the non-default settings for the startup banner ("" when all are defaults)
+func (n *NetworkSettings) Describe(nc NetworkConfig) string {
+	var parts []string
+	if n.ProxyURL != nil {
+		redacted := *n.ProxyURL
+		if redacted.User != nil {
+			redacted.User = url.User("***")
+		}
+		parts = append(parts, "proxy "+redacted.String())
+	}
+	if nc.CABundle != "" {
+		parts = append(parts, "CA bundle "+nc.CABundle)
+	}
+	if len(n.TLSConfig.Certificates) > 0 {
+		parts = append(parts, "mTLS client cert "+nc.ClientCert)
+	}
+	if nc.MinTLSVersion != "" {
+		parts = append(parts, "TLS >= "+nc.MinTLSVersion)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Optimized HTTP client with connection pooling
+// maxConns: maximum connections to allow (0 = use default of 100)
+func createHTTPClient(maxConns int) *http.Client {
+	if maxConns <= 0 {
+		maxConns = 100
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        maxConns,
+		MaxIdleConnsPerHost: maxConns,
+		MaxConnsPerHost:     maxConns,
+		IdleConnTimeout:     90 * time.Second,
+		DisableCompression:  false,
+		DisableKeepAlives:   false,
+		ForceAttemptHTTP2:   true, // Enable HTTP/2
+	}
+	networkSettings.Apply(transport)
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   120 * time.Second,
+	}
+}
+
+// ServiceAccountCredentials mirrors the credentials.json file downloaded for a Skyflow service account
+type ServiceAccountCredentials struct {
+	ClientID   string `json:"clientID"`
+	ClientName string `json:"clientName"`
+	KeyID      string `json:"keyID"`
+	TokenURI   string `json:"tokenURI"`
+	PrivateKey string `json:"privateKey"`
+}
+
+const (
+	tokenRefreshMargin   = 5 * time.Minute // Refresh minted tokens this long before they expire
+	defaultTokenLifetime = time.Hour       // Assumed lifetime when the access token has no readable exp claim
+)
+
+// TokenProvider supplies bearer tokens for Skyflow API calls.
+// A static token is returned as-is; service account credentials are exchanged for
+// a bearer token that is cached and re-minted shortly before it expires.
+type TokenProvider struct {
+	staticToken string
+	creds       *ServiceAccountCredentials
+	privateKey  *rsa.PrivateKey
+	client      *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewStaticTokenProvider wraps a fixed bearer token (no refresh possible)
+func NewStaticTokenProvider(token string) *TokenProvider {
+	return &TokenProvider{staticToken: token}
+}
+
+// NewServiceAccountTokenProvider loads a credentials.json file and prepares JWT signing
+func NewServiceAccountTokenProvider(credentialsPath string) (*TokenProvider, error) {
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var creds ServiceAccountCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file: %w", err)
+	}
+	if creds.ClientID == "" || creds.KeyID == "" || creds.TokenURI == "" || creds.PrivateKey == "" {
+		return nil, fmt.Errorf("credentials file must contain clientID, keyID, tokenURI and privateKey")
+	}
+
+	privateKey, err := parseRSAPrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenProvider{
+		creds:      &creds,
+		privateKey: privateKey,
+		client:     createTokenHTTPClient(),
+	}, nil
+}
+
+// createTokenHTTPClient builds the client used to mint bearer tokens (same proxy/TLS settings as API calls)
+func createTokenHTTPClient() *http.Client {
+	client := createHTTPClient(4)
+	client.Timeout = 30 * time.Second
+	return client
+}
+
+// parseRSAPrivateKey decodes a PEM private key in PKCS#8 or PKCS#1 form
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+
+	rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return rsaKey, nil
+}
+
+// Kind names the credential type for reports: service_account, bearer_token or none
+func (p *TokenProvider) Kind() string {
+	switch {
+	case p == nil:
+		return "none"
+	case p.creds != nil:
+		return "service_account"
+	case p.staticToken != "":
+		return "bearer_token"
+	}
+	return "none"
+}
+
+// CanRefresh reports whether a rejected token can be replaced with a freshly minted one
+func (p *TokenProvider) CanRefresh() bool {
+	return p.creds != nil
+}
+
+// Token returns a valid bearer token, minting a new one if the cached token is near expiry
+func (p *TokenProvider) Token() (string, error) {
+	if p.creds == nil {
+		return p.staticToken, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Until(p.expiry) > tokenRefreshMargin {
+		return p.token, nil
+	}
+
+	token, expiry, err := p.mintToken()
+	if err != nil {
+		return "", err
+	}
+	p.token = token
+	p.expiry = expiry
+	fmt.Printf("🔑 Minted Skyflow bearer token for %s (expires %s)\n",
+		p.creds.ClientName, expiry.Format("15:04:05"))
+	return token, nil
+}
+
+// Invalidate drops the cached token if it is still the one that was rejected,
+// so the next Token() call mints a replacement (concurrent workers only refresh once)
+func (p *TokenProvider) Invalidate(rejected string) {
+	if p.creds == nil {
+		return
+	}
+	p.mu.Lock()
+	if p.token == rejected {
+		p.token = ""
+	}
+	p.mu.Unlock()
+}
+
+// mintToken signs a JWT assertion with the service account key and exchanges it at the token URI
+func (p *TokenProvider) mintToken() (string, time.Time, error) {
+	assertion, err := p.signAssertion()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	body, _ := json.Marshal(map[string]string{
+		"grant_type": "urn:ietf:params:oauth:grant-type:jwt-bearer",
+		"assertion":  assertion,
+	})
+
+	req, err := http.NewRequest("POST", p.creds.TokenURI, bytes.NewReader(body))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		return "", time.Time{}, fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"accessToken"`
+		TokenType   string `json:"tokenType"`
+	}
+	if err := json.Unmarshal(respBody, &tokenResp); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("token response did not contain an access token")
+	}
+
+	return tokenResp.AccessToken, tokenExpiry(tokenResp.AccessToken), nil
+}
+
+// signAssertion builds the RS256-signed JWT that Skyflow expects for the jwt-bearer grant
+func (p *TokenProvider) signAssertion() (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": p.creds.ClientID,
+		"key": p.creds.KeyID,
+		"aud": p.creds.TokenURI,
+		"sub": p.creds.ClientID,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT assertion: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// tokenExpiry reads the exp claim from a JWT access token (falls back to the default lifetime)
+func tokenExpiry(accessToken string) time.Time {
+	fallback := time.Now().Add(defaultTokenLifetime)
+
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return fallback
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fallback
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return fallback
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// Generate unique suffix (optimized with pre-allocated buffer)
+var suffixChars = []byte("abcdefghijklmnopqrstuvwxyz0123456789")
+
+// Pool of random number generators to avoid global lock contention
+var randPool = sync.Pool{
+	New: func() interface{} {
+		return mathrand.New(mathrand.NewSource(time.Now().UnixNano()))
+	},
+}
+
+// appendUniqueSuffix appends "<unix timestamp>_<16 random chars>" to dst without allocating
+func appendUniqueSuffix(dst []byte) []byte {
+	// Get per-goroutine random source from pool (avoids global lock)
+	rng := randPool.Get().(*mathrand.Rand)
+	defer randPool.Put(rng)
+
+	// Avoid fmt.Sprintf - append with strconv
+	dst = strconv.AppendInt(dst, time.Now().Unix(), 10)
+	dst = append(dst, '_')
+	for i := 0; i < 16; i++ {
+		dst = append(dst, suffixChars[rng.Intn(len(suffixChars))])
+	}
+	return dst
+}
+
+// runTagPattern keeps run tags safe to embed in vault values and tokens
+var runTagPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// SuffixGenerator produces the -append-suffix suffix for values and tokens:
+//   - random: "<unix timestamp>_<16 random chars>", different on every run
+//   - deterministic: "<run tag>_<16 hex chars of HMAC-SHA256(run tag, value)>", reproducible
+//   - run-tag: the run tag itself
+type SuffixGenerator struct {
+	Strategy string
+	RunTag   string // HMAC key and suffix prefix (deterministic) or the suffix (run-tag); empty for random
+	hmacPool sync.Pool
+}
+
+// NewSuffixGenerator validates the strategy and run tag. A run tag without a strategy selects
+// run-tag; deterministic without a run tag generates one from the current time.
+func NewSuffixGenerator(strategy, runTag string) (*SuffixGenerator, error) {
+	if strategy == "" {
+		strategy = "random"
+		if runTag != "" {
+			strategy = "run-tag"
+		}
+	}
+	switch strategy {
+	case "random":
+		if runTag != "" {
+			return nil, fmt.Errorf("a run tag needs the deterministic or run-tag suffix strategy")
+		}
+	case "deterministic":
+		if runTag == "" {
+			runTag = "run" + time.Now().Format("20060102150405")
+		}
+	case "run-tag":
+		if runTag == "" {
+			return nil, fmt.Errorf("the run-tag suffix strategy needs a run tag (-run-tag)")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported suffix strategy %q (use random, deterministic or run-tag)", strategy)
+	}
+	if runTag != "" && !runTagPattern.MatchString(runTag) {
+		return nil, fmt.Errorf("invalid run tag %q (1-64 letters, digits, '_' or '-')", runTag)
+	}
+
+	g := &SuffixGenerator{Strategy: strategy, RunTag: runTag}
+	key := []byte(runTag)
+	g.hmacPool.New = func() interface{} { return hmac.New(sha256.New, key) }
+	return g, nil
+}
+
+// Reproducible reports whether the same source and run tag always produce the same suffixes
+func (g *SuffixGenerator) Reproducible() bool {
+	return g != nil && g.Strategy != "random"
+}
+
+// Append appends the suffix for s to dst (the caller writes the "_" separator).
+// A nil generator behaves like the random strategy.
+func (g *SuffixGenerator) Append(dst []byte, s string) []byte {
+	if g == nil || g.Strategy == "random" {
+		return appendUniqueSuffix(dst)
+	}
+	dst = append(dst, g.RunTag...)
+	if g.Strategy == "run-tag" {
+		return dst
+	}
+
+	mac := g.hmacPool.Get().(hash.Hash)
+	defer g.hmacPool.Put(mac)
+	mac.Reset()
+	mac.Write([]byte(s))
+	var sum [sha256.Size]byte
+	dst = append(dst, '_')
+	return hex.AppendEncode(dst, mac.Sum(sum[:0])[:8])
+}
+
+// Apply returns s with its suffix, as sent to the vault
+func (g *SuffixGenerator) Apply(s string) string {
+	return s + "_" + string(g.Append(nil, s))
+}
+
+// TransformSpec is one step of a vault's value transform chain (config.json)
+type TransformSpec struct {
+	Type        string   `json:"type"`                  // trim, upper, lower, digits_only, date, regex_replace, normalize
+	From        []string `json:"from,omitempty"`        // date: accepted input layouts (Go reference time, e.g. "01/02/2006")
+	To          string   `json:"to,omitempty"`          // date: output layout (e.g. "2006-01-02")
+	Pattern     string   `json:"pattern,omitempty"`     // regex_replace: regular expression
+	Replacement string   `json:"replacement,omitempty"` // regex_replace: replacement ($1 expands groups)
+	Form        string   `json:"form,omitempty"`        // normalize: NFC (default), NFD, NFKC or NFKD
+}
+
+// TransformStep is a compiled transform with its own counters
+type TransformStep struct {
+	Name    string
+	apply   func(string) (string, bool) // false: value could not be transformed and is passed on unchanged
+	Changed int64
+	Failed  int64
+}
+
+// TransformChain applies a vault's transforms to record values (tokens are never transformed)
+type TransformChain struct {
+	Steps       []*TransformStep
+	Columns     map[string][]*TransformStep // Extra steps per vault column (multi-column mappings)
+	Transformed int64                       // Values changed by at least one step
+	Untouched   int64                       // Values no step changed
+	Failed      int64                       // Values at least one step could not transform
+}
+
+// compileTransform validates a transform spec and builds its step
+func compileTransform(spec TransformSpec) (*TransformStep, error) {
+	step := &TransformStep{Name: spec.Type}
+
+	switch spec.Type {
+	case "trim":
+		step.apply = func(v string) (string, bool) { return strings.TrimSpace(v), true }
+	case "upper":
+		step.apply = func(v string) (string, bool) { return strings.ToUpper(v), true }
+	case "lower":
+		step.apply = func(v string) (string, bool) { return strings.ToLower(v), true }
+	case "digits_only":
+		step.apply = func(v string) (string, bool) {
+			return strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, v), true
+		}
+	case "date":
+		if len(spec.From) == 0 || spec.To == "" {
+			return nil, fmt.Errorf("date transform needs from (input layouts) and to (output layout)")
+		}
+		step.Name = fmt.Sprintf("date(%s → %s)", strings.Join(spec.From, "|"), spec.To)
+		step.apply = func(v string) (string, bool) {
+			if v == "" {
+				return v, true
+			}
+			for _, layout := range spec.From {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t.Format(spec.To), true
+				}
+			}
+			return v, false
+		}
+	case "regex_replace":
+		re, err := regexp.Compile(spec.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex_replace pattern %q: %w", spec.Pattern, err)
+		}
+		step.Name = fmt.Sprintf("regex_replace(%s)", spec.Pattern)
+		step.apply = func(v string) (string, bool) { return re.ReplaceAllString(v, spec.Replacement), true }
+	case "normalize":
+		form := norm.NFC
+		switch strings.ToUpper(spec.Form) {
+		case "", "NFC":
+		case "NFD":
+			form = norm.NFD
+		case "NFKC":
+			form = norm.NFKC
+		case "NFKD":
+			form = norm.NFKD
+		default:
+			return nil, fmt.Errorf("unknown normalize form %q (use NFC, NFD, NFKC or NFKD)", spec.Form)
+		}
+		step.Name = fmt.Sprintf("normalize(%s)", strings.ToUpper(cmp.Or(spec.Form, "NFC")))
+		step.apply = func(v string) (string, bool) { return form.String(v), true }
+	default:
+		return nil, fmt.Errorf("unknown transform type %q (use trim, upper, lower, digits_only, date, regex_replace or normalize)", spec.Type)
+	}
+	return step, nil
+}
+
+// compileVaultTransforms builds the vault's transform chain (nil when none are configured)
+func compileVaultTransforms(vaultConfig VaultConfig) (*TransformChain, error) {
+	chain := &TransformChain{Columns: make(map[string][]*TransformStep)}
+	for _, spec := range vaultConfig.Transforms {
+		step, err := compileTransform(spec)
+		if err != nil {
+			return nil, err
+		}
+		chain.Steps = append(chain.Steps, step)
+	}
+	for _, mapping := range vaultConfig.Columns {
+		for _, spec := range mapping.Transforms {
+			step, err := compileTransform(spec)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", mapping.Column, err)
+			}
+			chain.Columns[mapping.Column] = append(chain.Columns[mapping.Column], step)
+		}
+	}
+	if len(chain.Steps) == 0 && len(chain.Columns) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
+// Apply runs the vault steps, then the column's own steps, over one value
+func (c *TransformChain) Apply(column, value string) string {
+	original := value
+	failed := false
+	run := func(steps []*TransformStep) {
+		for _, step := range steps {
+			out, ok := step.apply(value)
+			if !ok {
+				failed = true
+				atomic.AddInt64(&step.Failed, 1)
+				continue
+			}
+			if out != value {
+				atomic.AddInt64(&step.Changed, 1)
+				value = out
+			}
+		}
+	}
+	run(c.Steps)
+	run(c.Columns[column])
+
+	if failed {
+		atomic.AddInt64(&c.Failed, 1)
+	}
+	if value != original {
+		atomic.AddInt64(&c.Transformed, 1)
+	} else {
+		atomic.AddInt64(&c.Untouched, 1)
+	}
+	return value
+}
+
+// ApplyRecords transforms record values in place, spread across all CPUs
+func (c *TransformChain) ApplyRecords(records []Record, column string) {
+	workers := runtime.NumCPU()
+	chunk := (len(records) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(records); start += chunk {
+		end := min(start+chunk, len(records))
+		wg.Add(1)
+		go func(part []Record) {
+			defer wg.Done()
+			for i := range part {
+				if len(part[i].Fields) > 0 {
+					for j := range part[i].Fields {
+						part[i].Fields[j].Value = c.Apply(part[i].Fields[j].Column, part[i].Fields[j].Value)
+					}
+				} else {
+					part[i].Value = c.Apply(column, part[i].Value)
+				}
+			}
+		}(records[start:end])
+	}
+	wg.Wait()
+}
+
+// applyVaultTransforms normalizes freshly read source records. Error-log records were
+// transformed before they failed, so they are replayed as-is.
+func applyVaultTransforms(vaultConfig VaultConfig, dataSource DataSource, records []Record) (*TransformChain, error) {
+	if _, replay := dataSource.(*ErrorLogDataSource); replay {
+		return nil, nil
+	}
+	chain, err := compileVaultTransforms(vaultConfig)
+	if err != nil || chain == nil {
+		return nil, err
+	}
+	chain.ApplyRecords(records, vaultConfig.Column)
+	return chain, nil
+}
+
+// ValidationRule is one per-vault data quality check (config.json)
+type ValidationRule struct {
+	Type     string `json:"type"`               // regex, length, date_range, luhn, token_pattern
+	Pattern  string `json:"pattern,omitempty"`  // regex / token_pattern: expression the whole value/token must match
+	Min      int    `json:"min,omitempty"`      // length: minimum characters
+	Max      int    `json:"max,omitempty"`      // length: maximum characters (0 = no limit)
+	Layout   string `json:"layout,omitempty"`   // date_range: Go layout of the value (default 2006-01-02)
+	Earliest string `json:"earliest,omitempty"` // date_range: oldest allowed date (in layout, or "today")
+	Latest   string `json:"latest,omitempty"`   // date_range: newest allowed date (in layout, or "today")
+}
+
+// ValidationCheck is a compiled rule with its rejection counter
+type ValidationCheck struct {
+	Name     string
+	check    func(value, token string) string // Returns why the pair fails, or "" when it passes
+	Rejected int64
+}
+
+// RecordValidator rejects records that fail a vault's validation rules
+type RecordValidator struct {
+	Checks    []*ValidationCheck
+	Columns   map[string][]*ValidationCheck // Extra checks per vault column (multi-column mappings)
+	UpsertKey *ValidationCheck              // Multi-column upsert: rejects rows without the upsert key column
+	KeyColumn string
+	Checked   int64
+	Rejected  int64
+}
+
+// RejectedRecord is one line of the reject file
+type RejectedRecord struct {
+	Vault  string `json:"vault"`
+	Row    int    `json:"row"` // 1-based position in the records read from the source
+	Column string `json:"column"`
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+	Record Record `json:"record"`
+}
+
+// luhnValid reports whether a digit string passes the Luhn (mod 10) checksum
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// parseDateBound parses a date_range bound ("today" is the current date)
+func parseDateBound(layout, bound string) (time.Time, error) {
+	if strings.EqualFold(bound, "today") {
+		now := time.Now().UTC()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse(layout, bound)
+}
+
+// compileValidationRule validates a rule and builds its check
+func compileValidationRule(rule ValidationRule) (*ValidationCheck, error) {
+	check := &ValidationCheck{Name: rule.Type}
+
+	switch rule.Type {
+	case "regex", "token_pattern":
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("%s rule needs a pattern", rule.Type)
+		}
+		re, err := regexp.Compile("^(?:" + rule.Pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", rule.Type, rule.Pattern, err)
+		}
+		check.Name = fmt.Sprintf("%s(%s)", rule.Type, rule.Pattern)
+		if rule.Type == "regex" {
+			check.check = func(value, token string) string {
+				if value == "" || re.MatchString(value) {
+					return ""
+				}
+				return "value does not match " + rule.Pattern
+			}
+		} else {
+			check.check = func(value, token string) string {
+				if token == "" || re.MatchString(token) {
+					return ""
+				}
+				return "token does not match " + rule.Pattern
+			}
+		}
+	case "length":
+		if rule.Min < 0 || rule.Max < 0 || (rule.Max > 0 && rule.Max < rule.Min) {
+			return nil, fmt.Errorf("invalid length bounds min=%d max=%d", rule.Min, rule.Max)
+		}
+		check.Name = fmt.Sprintf("length(%d-%d)", rule.Min, rule.Max)
+		if rule.Max == 0 {
+			check.Name = fmt.Sprintf("length(%d+)", rule.Min)
+		}
+		check.check = func(value, token string) string {
+			if value == "" {
+				return ""
+			}
+			n := utf8.RuneCountInString(value)
+			if n < rule.Min || (rule.Max > 0 && n > rule.Max) {
+				return fmt.Sprintf("length %d outside %d-%d", n, rule.Min, rule.Max)
+			}
+			return ""
+		}
+	case "date_range":
+		layout := cmp.Or(rule.Layout, "2006-01-02")
+		var earliest, latest time.Time
+		var err error
+		if rule.Earliest != "" {
+			if earliest, err = parseDateBound(layout, rule.Earliest); err != nil {
+				return nil, fmt.Errorf("invalid date_range earliest %q: %w", rule.Earliest, err)
+			}
+		}
+		if rule.Latest != "" {
+			if latest, err = parseDateBound(layout, rule.Latest); err != nil {
+				return nil, fmt.Errorf("invalid date_range latest %q: %w", rule.Latest, err)
+			}
+		}
+		check.Name = fmt.Sprintf("date_range(%s..%s)", rule.Earliest, rule.Latest)
+		check.check = func(value, token string) string {
+			if value == "" {
+				return ""
+			}
+			t, err := time.Parse(layout, value)
+			if err != nil {
+				return fmt.Sprintf("not a date in layout %s", layout)
+			}
+			if !earliest.IsZero() && t.Before(earliest) {
+				return fmt.Sprintf("date before %s", rule.Earliest)
+			}
+			if !latest.IsZero() && t.After(latest) {
+				return fmt.Sprintf("date after %s", rule.Latest)
+			}
+			return ""
+		}
+	case "luhn":
+		check.check = func(value, token string) string {
+			if value == "" {
+				return ""
+			}
+			digits := strings.NewReplacer(" ", "", "-", "").Replace(value)
+			if len(digits) < 2 || strings.Trim(digits, "0123456789") != "" {
+				return "not a digit string"
+			}
+			if !luhnValid(digits) {
+				return "Luhn checksum failed"
+			}
+			return ""
+		}
+	default:
+		return nil, fmt.Errorf("unknown validation rule %q (use regex, length, date_range, luhn or token_pattern)", rule.Type)
+	}
+	return check, nil
+}
+
+// compileVaultValidation builds the vault's validator (nil when no rules are configured). Upserts
+// of multi-column rows always get one: a row without its upsert key would be sent without the key.
+func compileVaultValidation(vaultConfig VaultConfig, upsert bool) (*RecordValidator, error) {
+	validator := &RecordValidator{Columns: make(map[string][]*ValidationCheck)}
+	if upsert && vaultConfig.IsMultiColumn() {
+		validator.UpsertKey = &ValidationCheck{Name: fmt.Sprintf("upsert_key(%s)", vaultConfig.Column)}
+		validator.KeyColumn = vaultConfig.Column
+	}
+	for _, rule := range vaultConfig.Validation {
+		check, err := compileValidationRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		validator.Checks = append(validator.Checks, check)
+	}
+	for _, mapping := range vaultConfig.Columns {
+		for _, rule := range mapping.Validation {
+			check, err := compileValidationRule(rule)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", mapping.Column, err)
+			}
+			validator.Columns[mapping.Column] = append(validator.Columns[mapping.Column], check)
+		}
+	}
+	if len(validator.Checks) == 0 && len(validator.Columns) == 0 && validator.UpsertKey == nil {
+		return nil, nil
+	}
+	return validator, nil
+}
+
+// checkPair returns the first failing check for one value/token pair
+func (v *RecordValidator) checkPair(column, value, token string) (*ValidationCheck, string) {
+	for _, checks := range [][]*ValidationCheck{v.Checks, v.Columns[column]} {
+		for _, check := range checks {
+			if reason := check.check(value, token); reason != "" {
+				return check, reason
+			}
+		}
+	}
+	return nil, ""
+}
+
+// Filter splits records into those passing every rule and the rejected ones, returning the
+// source rows of the kept records. A multi-column row is rejected as a whole when any field fails.
+func (v *RecordValidator) Filter(vaultConfig VaultConfig, records []Record) ([]Record, []int, []RejectedRecord) {
+	kept := records[:0:0]
+	var rows []int
+	var rejected []RejectedRecord
+	for i, record := range records {
+		var failed *ValidationCheck
+		var column, reason string
+		if v.UpsertKey != nil && !slices.ContainsFunc(record.Fields, func(f Field) bool { return f.Column == v.KeyColumn }) {
+			failed, column, reason = v.UpsertKey, v.KeyColumn, "upsert key column is empty"
+		} else if len(record.Fields) > 0 {
+			for _, field := range record.Fields {
+				if failed, reason = v.checkPair(field.Column, field.Value, field.Token); failed != nil {
+					column = field.Column
+					break
+				}
+			}
+		} else {
+			column = vaultConfig.Column
+			failed, reason = v.checkPair(column, record.Value, record.Token)
+		}
+
+		if failed == nil {
+			kept = append(kept, record)
+			rows = append(rows, i+1)
+			continue
+		}
+		failed.Rejected++
+		rejected = append(rejected, RejectedRecord{
+			Vault: vaultConfig.Name, Row: i + 1, Column: column,
+			Rule: failed.Name, Reason: reason, Record: record,
+		})
+	}
+	v.Checked += int64(len(records))
+	v.Rejected += int64(len(rejected))
+	return kept, rows, rejected
+}
+
+// applyVaultValidation drops records failing the vault's rules, writing them to rejects when set.
+// The returned rows map kept records to source rows (nil when nothing was filtered).
+// Error-log records already passed validation, so they are replayed as-is.
+func applyVaultValidation(vaultConfig VaultConfig, dataSource DataSource, records []Record, rejects *RejectSink, upsert bool) ([]Record, []int, *RecordValidator, error) {
+	if _, replay := dataSource.(*ErrorLogDataSource); replay {
+		return records, nil, nil, nil
+	}
+	validator, err := compileVaultValidation(vaultConfig, upsert)
+	if err != nil || validator == nil {
+		return records, nil, nil, err
+	}
+	kept, rows, rejected := validator.Filter(vaultConfig, records)
+	if rejects != nil && len(rejected) > 0 {
+		if err := rejects.Write(rejected); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return kept, rows, validator, nil
+}
+
+// RejectSink writes records that failed validation to an NDJSON file.
+// The file is only created once something is rejected.
+type RejectSink struct {
+	Path    string
+	mu      sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	Records int64
+}
+
+// Write appends rejected records, one JSON object per line
+func (s *RejectSink) Write(rejected []RejectedRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		// Rejected rows carry raw values, so keep the file private like the source data
+		file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create reject file: %w", err)
+		}
+		s.file = file
+		s.writer = bufio.NewWriterSize(file, 256*1024)
+	}
+
+	encoder := json.NewEncoder(s.writer)
+	for _, r := range rejected {
+		if err := encoder.Encode(r); err != nil {
+			return fmt.Errorf("failed to write reject file: %w", err)
+		}
+	}
+	s.Records += int64(len(rejected))
+	return s.writer.Flush()
+}
+
+// Close closes the reject file if one was created
+func (s *RejectSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+const (
+	dedupBuckets     = 64   // On-disk dedup partitions (each is sorted in memory on its own)
+	dedupReportLimit = 1000 // Conflicts listed per vault in the conflict report
+)
+
+// Dedup index kinds: whole records, value → token, token → value
+const (
+	dedupRecord = iota
+	dedupValue
+	dedupToken
+	dedupKinds
+)
+
+// dedupEntry is one hashed key with the hash it maps to and the record it came from
+type dedupEntry struct {
+	Key    [32]byte
+	Other  [32]byte
+	Index  uint32
+	Column uint16 // Index into the vault's columns (0 for single-column vaults)
+}
+
+const dedupEntrySize = 32 + 32 + 4 + 2
+
+// dedupIndex collects entries and hands them back grouped by key, in record order
+type dedupIndex interface {
+	Add(kind int, entry dedupEntry) error
+	Groups(kind int, fn func(group []dedupEntry)) error
+	Close() error
+}
+
+// sortDedupEntries orders entries by key, then by record
+func sortDedupEntries(entries []dedupEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if c := bytes.Compare(entries[i].Key[:], entries[j].Key[:]); c != 0 {
+			return c < 0
+		}
+		return entries[i].Index < entries[j].Index
+	})
+}
+
+// eachDedupGroup calls fn for every run of entries sharing a key
+func eachDedupGroup(entries []dedupEntry, fn func(group []dedupEntry)) {
+	sortDedupEntries(entries)
+	for start := 0; start < len(entries); {
+		end := start + 1
+		for end < len(entries) && entries[end].Key == entries[start].Key {
+			end++
+		}
+		fn(entries[start:end])
+		start = end
+	}
+}
+
+// memoryDedupIndex keeps every entry in memory
+type memoryDedupIndex struct {
+	entries [dedupKinds][]dedupEntry
+}
+
+func (m *memoryDedupIndex) Add(kind int, entry dedupEntry) error {
+	m.entries[kind] = append(m.entries[kind], entry)
+	return nil
+}
+
+func (m *memoryDedupIndex) Groups(kind int, fn func(group []dedupEntry)) error {
+	eachDedupGroup(m.entries[kind], fn)
+	m.entries[kind] = nil
+	return nil
+}
+
+func (m *memoryDedupIndex) Close() error { return nil }
+
+// diskDedupIndex spills entries to bucket files partitioned by key, so only one
+// bucket is held in memory at a time
+type diskDedupIndex struct {
+	dir     string
+	files   [dedupKinds][dedupBuckets]*os.File
+	writers [dedupKinds][dedupBuckets]*bufio.Writer
+}
+
+func newDiskDedupIndex(parent string) (*diskDedupIndex, error) {
+	dir, err := os.MkdirTemp(parent, "byot-dedup-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dedup directory: %w", err)
+	}
+	d := &diskDedupIndex{dir: dir}
+	for kind := 0; kind < dedupKinds; kind++ {
+		for bucket := 0; bucket < dedupBuckets; bucket++ {
+			file, err := os.Create(filepath.Join(dir, fmt.Sprintf("%d_%02d.bin", kind, bucket)))
+			if err != nil {
+				d.Close()
+				return nil, fmt.Errorf("failed to create dedup bucket: %w", err)
+			}
+			d.files[kind][bucket] = file
+			d.writers[kind][bucket] = bufio.NewWriterSize(file, 64*1024)
+		}
+	}
+	return d, nil
+}
+
+func (d *diskDedupIndex) Add(kind int, entry dedupEntry) error {
+	var buf [dedupEntrySize]byte
+	copy(buf[0:32], entry.Key[:])
+	copy(buf[32:64], entry.Other[:])
+	binary.LittleEndian.PutUint32(buf[64:68], entry.Index)
+	binary.LittleEndian.PutUint16(buf[68:70], entry.Column)
+	if _, err := d.writers[kind][entry.Key[0]%dedupBuckets].Write(buf[:]); err != nil {
+		return fmt.Errorf("failed to write dedup bucket: %w", err)
+	}
+	return nil
+}
+
+func (d *diskDedupIndex) Groups(kind int, fn func(group []dedupEntry)) error {
+	for bucket := 0; bucket < dedupBuckets; bucket++ {
+		if err := d.writers[kind][bucket].Flush(); err != nil {
+			return fmt.Errorf("failed to flush dedup bucket: %w", err)
+		}
+		file := d.files[kind][bucket]
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind dedup bucket: %w", err)
+		}
+		data, err := io.ReadAll(bufio.NewReader(file))
+		if err != nil {
+			return fmt.Errorf("failed to read dedup bucket: %w", err)
+		}
+
+		entries := make([]dedupEntry, len(data)/dedupEntrySize)
+		for i := range entries {
+			b := data[i*dedupEntrySize:]
+			copy(entries[i].Key[:], b[0:32])
+			copy(entries[i].Other[:], b[32:64])
+			entries[i].Index = binary.LittleEndian.Uint32(b[64:68])
+			entries[i].Column = binary.LittleEndian.Uint16(b[68:70])
+		}
+		eachDedupGroup(entries, fn)
+	}
+	return nil
+}
+
+func (d *diskDedupIndex) Close() error {
+	for kind := range d.files {
+		for _, file := range d.files[kind] {
+			if file != nil {
+				file.Close()
+			}
+		}
+	}
+	return os.RemoveAll(d.dir)
+}
+
+// DedupConflict is one value seen with several tokens, or one token seen with several values
+type DedupConflict struct {
+	Kind   string   `json:"kind"` // value_multiple_tokens or token_multiple_values
+	Column string   `json:"column"`
+	Rows   []int    `json:"rows"`             // Source rows involved (1-based)
+	Tokens []string `json:"tokens,omitempty"` // Redacted tokens of the value (value_multiple_tokens)
+	Token  string   `json:"token,omitempty"`  // Redacted shared token (token_multiple_values)
+	Values int      `json:"values,omitempty"` // Distinct values for the token (token_multiple_values)
+}
+
+// VaultDedup is the deduplication outcome for one vault
+type VaultDedup struct {
+	Vault            string          `json:"vault"`
+	Records          int             `json:"records"`
+	Duplicates       int             `json:"duplicates_dropped"`
+	ValueConflicts   int             `json:"value_conflicts"`
+	TokenConflicts   int             `json:"token_conflicts"`
+	ConflictsDropped int             `json:"conflict_records_dropped"`
+	Kept             int             `json:"records_kept"`
+	Conflicts        []DedupConflict `json:"conflicts"`
+	Truncated        bool            `json:"conflicts_truncated,omitempty"`
+}
+
+// ConflictReport is the JSON report of duplicates and conflicts across vaults
+type ConflictReport struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Mode        string        `json:"mode"`
+	Policy      string        `json:"policy"`
+	Vaults      []*VaultDedup `json:"vaults"`
+}
+
+// Deduplicator drops exact duplicate records and resolves token/value conflicts
+// between ReadRecords and batching
+type Deduplicator struct {
+	Mode       string // memory or disk
+	Policy     string // skip, fail or keep-first
+	Dir        string // Parent directory for disk buckets
+	ReportPath string // Conflict report (written when duplicates or conflicts are found)
+	mu         sync.Mutex
+	report     ConflictReport
+}
+
+// NewDeduplicator validates the dedup mode and conflict policy
+func NewDeduplicator(mode, policy, dir, reportPath string) (*Deduplicator, error) {
+	if mode != "memory" && mode != "disk" {
+		return nil, fmt.Errorf("unsupported dedup mode %q (use off, memory or disk)", mode)
+	}
+	if policy != "skip" && policy != "fail" && policy != "keep-first" {
+		return nil, fmt.Errorf("unsupported dedup policy %q (use skip, fail or keep-first)", policy)
+	}
+	return &Deduplicator{
+		Mode: mode, Policy: policy, Dir: dir, ReportPath: reportPath,
+		report: ConflictReport{Mode: mode, Policy: policy, Vaults: []*VaultDedup{}},
+	}, nil
+}
+
+// Apply deduplicates records (rows maps them to source rows; nil means identity) and
+// adds the outcome to the conflict report. A nil Deduplicator and error-log replays
+// pass records through unchanged. Under the fail policy an error is returned when any
+// conflict is found.
+func (d *Deduplicator) Apply(vaultConfig VaultConfig, dataSource DataSource, records []Record, rows []int) ([]Record, *VaultDedup, error) {
+	kept, result, err := d.Filter(vaultConfig, dataSource, records, rows)
+	if result == nil {
+		return kept, nil, err
+	}
+	if recordErr := d.record(result); recordErr != nil {
+		fmt.Printf("  ⚠️  Failed to write conflict report: %v\n", recordErr)
+	}
+	if err != nil && d.ReportPath != "" {
+		err = fmt.Errorf("%w; see %s", err, d.ReportPath)
+	}
+	return kept, result, err
+}
+
+// Filter deduplicates records like Apply without touching the conflict report, so
+// verify and reconcile can mirror a load's filtering read-only
+func (d *Deduplicator) Filter(vaultConfig VaultConfig, dataSource DataSource, records []Record, rows []int) ([]Record, *VaultDedup, error) {
+	if d == nil {
+		return records, nil, nil
+	}
+	if _, replay := dataSource.(*ErrorLogDataSource); replay {
+		return records, nil, nil
+	}
+
+	var index dedupIndex = &memoryDedupIndex{}
+	if d.Mode == "disk" {
+		disk, err := newDiskDedupIndex(d.Dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		index = disk
+	}
+	defer index.Close()
+
+	// Column slots: single-column vaults use slot 0 for vaultConfig.Column
+	columns := []string{vaultConfig.Column}
+	slots := map[string]uint16{vaultConfig.Column: 0}
+	for _, mapping := range vaultConfig.Columns {
+		if _, ok := slots[mapping.Column]; !ok {
+			slots[mapping.Column] = uint16(len(columns))
+			columns = append(columns, mapping.Column)
+		}
+	}
+
+	pair := func(record Record, column uint16) (value, token string) {
+		if len(record.Fields) == 0 {
+			return record.Value, record.Token
+		}
+		for _, field := range record.Fields {
+			if slots[field.Column] == column {
+				return field.Value, field.Token
+			}
+		}
+		return "", ""
+	}
+
+	for i, record := range records {
+		fields := record.Fields
+		if len(fields) == 0 {
+			fields = []Field{{Column: vaultConfig.Column, Value: record.Value, Token: record.Token}}
+		}
+		parts := make([]string, 0, len(fields)*3)
+		for _, field := range fields {
+			parts = append(parts, field.Column, field.Value, field.Token)
+		}
+		if err := index.Add(dedupRecord, dedupEntry{Key: hashKey(parts...), Index: uint32(i)}); err != nil {
+			return nil, nil, err
+		}
+
+		for _, field := range fields {
+			if field.Value == "" || field.Token == "" {
+				continue
+			}
+			column := slots[field.Column]
+			valueKey := hashKey(field.Column, field.Value)
+			tokenKey := hashKey(field.Column, field.Token)
+			if err := index.Add(dedupValue, dedupEntry{Key: valueKey, Other: tokenKey, Index: uint32(i), Column: column}); err != nil {
+				return nil, nil, err
+			}
+			if err := index.Add(dedupToken, dedupEntry{Key: tokenKey, Other: valueKey, Index: uint32(i), Column: column}); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	result := &VaultDedup{Vault: vaultConfig.Name, Records: len(records), Conflicts: []DedupConflict{}}
+	row := func(i uint32) int {
+		if rows != nil {
+			return rows[i]
+		}
+		return int(i) + 1
+	}
+	duplicate := make([]bool, len(records))
+	conflicted := make([]bool, len(records))
+
+	// Exact duplicate records: keep the first occurrence
+	if err := index.Groups(dedupRecord, func(group []dedupEntry) {
+		for _, entry := range group[1:] {
+			duplicate[entry.Index] = true
+			result.Duplicates++
+		}
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	// Conflicts: a key mapping to more than one distinct hash
+	addConflict := func(kind int, group []dedupEntry) {
+		first := group[0]
+		distinct := map[[32]byte]struct{}{first.Other: {}}
+		for _, entry := range group[1:] {
+			distinct[entry.Other] = struct{}{}
+		}
+		if len(distinct) == 1 {
+			return
+		}
+
+		if kind == dedupValue {
+			result.ValueConflicts++
+		} else {
+			result.TokenConflicts++
+		}
+		for _, entry := range group {
+			if d.Policy == "skip" || (d.Policy == "keep-first" && entry.Other != first.Other) {
+				conflicted[entry.Index] = true
+			}
+		}
+
+		if len(result.Conflicts) >= dedupReportLimit {
+			result.Truncated = true
+			return
+		}
+		conflict := DedupConflict{Column: columns[first.Column]}
+		seen := make(map[string]bool)
+		for _, entry := range group {
+			if duplicate[entry.Index] {
+				continue
+			}
+			conflict.Rows = append(conflict.Rows, row(entry.Index))
+			_, token := pair(records[entry.Index], entry.Column)
+			if kind == dedupValue && !seen[token] {
+				seen[token] = true
+				conflict.Tokens = append(conflict.Tokens, redactToken(token))
+			}
+		}
+		if kind == dedupValue {
+			conflict.Kind = "value_multiple_tokens"
+		} else {
+			conflict.Kind = "token_multiple_values"
+			_, token := pair(records[first.Index], first.Column)
+			conflict.Token = redactToken(token)
+			conflict.Values = len(distinct)
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+	for _, kind := range []int{dedupValue, dedupToken} {
+		if err := index.Groups(kind, func(group []dedupEntry) { addConflict(kind, group) }); err != nil {
+			return nil, nil, err
+		}
+	}
+	sort.Slice(result.Conflicts, func(i, j int) bool { return result.Conflicts[i].Rows[0] < result.Conflicts[j].Rows[0] })
+
+	kept := records[:0:0]
+	for i, record := range records {
+		switch {
+		case duplicate[i]:
+		case conflicted[i]:
+			result.ConflictsDropped++
+		default:
+			kept = append(kept, record)
+		}
+	}
+	result.Kept = len(kept)
+
+	if conflicts := result.ValueConflicts + result.TokenConflicts; d.Policy == "fail" && conflicts > 0 {
+		return nil, result, fmt.Errorf("%d token/value conflicts found in %s (dedup policy fail)",
+			conflicts, vaultConfig.Name)
+	}
+	return kept, result, nil
+}
+
+// record adds a vault's outcome to the conflict report and rewrites it when there is anything to report
+func (d *Deduplicator) record(result *VaultDedup) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.report.Vaults = append(d.report.Vaults, result)
+
+	found := false
+	for _, v := range d.report.Vaults {
+		if v.Duplicates > 0 || v.ValueConflicts > 0 || v.TokenConflicts > 0 {
+			found = true
+		}
+	}
+	if !found || d.ReportPath == "" {
+		return nil
+	}
+	d.report.GeneratedAt = time.Now()
+	data, err := json.MarshalIndent(d.report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.ReportPath, append(data, '\n'), 0600)
+}
+
+// ErrorLogDataSource implements DataSource interface for error log JSON files
+type ErrorLogDataSource struct {
+	ErrorLogPath string
+	VaultName    string
+	VaultID      string
+	Table        string
+	Column       string
+	Records      []Record
+	ErrorLog     ErrorLogMetadata
+}
+
+// ErrorLogMetadata contains metadata about the error log
+type ErrorLogMetadata struct {
+	Timestamp     time.Time    `json:"timestamp"`
+	TotalErrors   int          `json:"total_errors"`
+	FailedRecords int          `json:"failed_records"`
+	Errors        []BatchError `json:"errors"`
+}
+
+// Connect loads and parses the error log file
+func (e *ErrorLogDataSource) Connect() error {
+	file, err := os.Open(e.ErrorLogPath)
+	if err != nil {
+		return fmt.Errorf("failed to open error log file: %w", err)
+	}
+	defer file.Close()
+
+	var errorLog struct {
+		VaultName     string       `json:"vault_name"`
+		VaultID       string       `json:"vault_id"`
+		Table         string       `json:"table"`
+		Column        string       `json:"column"`
+		Timestamp     time.Time    `json:"timestamp"`
+		TotalErrors   int          `json:"total_errors"`
+		FailedRecords int          `json:"failed_records"`
+		Errors        []BatchError `json:"errors"`
+	}
+
+	if err := json.NewDecoder(file).Decode(&errorLog); err != nil {
+		return fmt.Errorf("failed to parse error log: %w", err)
+	}
+
+	e.VaultName = errorLog.VaultName
+	e.VaultID = errorLog.VaultID
+	e.Table = errorLog.Table
+	e.Column = errorLog.Column
+	e.ErrorLog = ErrorLogMetadata{
+		Timestamp:     errorLog.Timestamp,
+		TotalErrors:   errorLog.TotalErrors,
+		FailedRecords: errorLog.FailedRecords,
+		Errors:        errorLog.Errors,
+	}
+
+	// Extract all records from all failed batches
+	for _, batchErr := range errorLog.Errors {
+		e.Records = append(e.Records, batchErr.Records...)
+	}
+
+	return nil
+}
+
+// Close is a no-op for error log files
+func (e *ErrorLogDataSource) Close() error {
+	return nil
+}
+
+// ReadRecords returns the records loaded from the error log
+func (e *ErrorLogDataSource) ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error) {
+	if maxRecords > 0 && maxRecords < len(e.Records) {
+		return e.Records[:maxRecords], nil
+	}
+	return e.Records, nil
+}
+
+// CSVDataSource implements DataSource interface for local CSV files
+type CSVDataSource struct {
+	DataDirectory string
+}
+
+// Connect validates CSV data directory exists
+func (c *CSVDataSource) Connect() error {
+	if _, err := os.Stat(c.DataDirectory); os.IsNotExist(err) {
+		return fmt.Errorf("data directory not found: %s", c.DataDirectory)
+	}
+	return nil
+}
+
+// Close is a no-op for CSV files
+func (c *CSVDataSource) Close() error {
+	return nil
+}
+
+// ReadRecords reads records from CSV files
+func (c *CSVDataSource) ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error) {
+	// Pre-allocate slice with capacity
+	capacity := maxRecords
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	records := make([]Record, 0, capacity)
+	err := c.scanRecords(vaultConfig, maxRecords, func(record Record) {
+		records = append(records, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SampleRecords streams the CSV files through a reservoir, so only sampleSize records are
+// held in memory
+func (c *CSVDataSource) SampleRecords(vaultConfig VaultConfig, maxRecords, sampleSize int) ([]Record, int, error) {
+	sample := newReservoir(sampleSize)
+	defer sample.Release()
+	if err := c.scanRecords(vaultConfig, maxRecords, sample.Add); err != nil {
+		return nil, 0, err
+	}
+	return sample.Records, sample.Seen, nil
+}
+
+// scanRecords streams records from the CSV files to emit, stopping after maxRecords
+func (c *CSVDataSource) scanRecords(vaultConfig VaultConfig, maxRecords int, emit func(Record)) error {
+	if vaultConfig.IsMultiColumn() {
+		return c.scanMultiColumnRecords(vaultConfig, maxRecords, emit)
+	}
+
+	// Construct file paths based on vault type
+	dataFilePath := fmt.Sprintf("%s/%s_data.csv", c.DataDirectory, vaultConfig.Column)
+	tokenFilePath := fmt.Sprintf("%s/%s_tokens.csv", c.DataDirectory, vaultConfig.Column)
+
+	// Open data file
+	dataFile, err := os.Open(dataFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open data file %s: %w", dataFilePath, err)
+	}
+	defer dataFile.Close()
+
+	// Open token file
+	tokenFile, err := os.Open(tokenFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open token file %s: %w", tokenFilePath, err)
+	}
+	defer tokenFile.Close()
+
+	dataReader := csv.NewReader(dataFile)
+	tokenReader := csv.NewReader(tokenFile)
+
+	// Optimize CSV reading
+	dataReader.ReuseRecord = true
+	tokenReader.ReuseRecord = true
+
+	// Read headers
+	dataHeaders, err := dataReader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read data headers: %w", err)
+	}
+	dataHeaders = append([]string(nil), dataHeaders...) // Copy headers
+
+	tokenHeaders, err := tokenReader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read token headers: %w", err)
+	}
+	tokenHeaders = append([]string(nil), tokenHeaders...) // Copy headers
+
+	// Find column indices
+	dataColIdx := -1
+	tokenColIdx := -1
+
+	dataColName := getDataColumnName(vaultConfig.Column)
+	tokenColName := getTokenColumnName(vaultConfig.Column)
+
+	for i, h := range dataHeaders {
+		if h == dataColName {
+			dataColIdx = i
+			break
+		}
+	}
+
+	for i, h := range tokenHeaders {
+		if h == tokenColName {
+			tokenColIdx = i
+			break
+		}
+	}
+
+	if dataColIdx == -1 || tokenColIdx == -1 {
+		return fmt.Errorf("column not found: data=%s token=%s", dataColName, tokenColName)
+	}
+
+	recordCount := 0
+
+	for {
+		if maxRecords > 0 && recordCount >= maxRecords {
+			break
+		}
+
+		dataRow, err := dataReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading data row: %w", err)
+		}
+
+		tokenRow, err := tokenReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading token row: %w", err)
+		}
+
+		if dataColIdx < len(dataRow) && tokenColIdx < len(tokenRow) {
+			value := dataRow[dataColIdx]
+			token := tokenRow[tokenColIdx]
+
+			if value != "" && token != "" {
+				// Copy strings to avoid retaining CSV reader's internal buffer
+				emit(Record{
+					Value: strings.Clone(value),
+					Token: strings.Clone(token),
+				})
+				recordCount++
+			}
+		}
+	}
+
+	return nil
+}
+
+// scanMultiColumnRecords reads <table>_data.csv / <table>_tokens.csv and emits one
+// record per row with a field for every mapped column
+func (c *CSVDataSource) scanMultiColumnRecords(vaultConfig VaultConfig, maxRecords int, emit func(Record)) error {
+	dataFilePath := fmt.Sprintf("%s/%s_data.csv", c.DataDirectory, vaultConfig.TableName())
+	tokenFilePath := fmt.Sprintf("%s/%s_tokens.csv", c.DataDirectory, vaultConfig.TableName())
+
+	dataFile, err := os.Open(dataFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open data file %s: %w", dataFilePath, err)
+	}
+	defer dataFile.Close()
+
+	tokenFile, err := os.Open(tokenFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open token file %s: %w", tokenFilePath, err)
+	}
+	defer tokenFile.Close()
+
+	dataReader := csv.NewReader(dataFile)
+	tokenReader := csv.NewReader(tokenFile)
+	dataReader.ReuseRecord = true
+	tokenReader.ReuseRecord = true
+
+	dataHeaders, err := dataReader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read data headers: %w", err)
+	}
+	tokenHeaders, err := tokenReader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read token headers: %w", err)
+	}
+
+	// Resolve header indices for every mapped column
+	indexOf := func(headers []string, name string) int {
+		for i, h := range headers {
+			if h == name {
+				return i
+			}
+		}
+		return -1
+	}
+	dataIdx := make([]int, len(vaultConfig.Columns))
+	tokenIdx := make([]int, len(vaultConfig.Columns))
+	for i, mapping := range vaultConfig.Columns {
+		dataIdx[i] = indexOf(dataHeaders, mapping.Source)
+		tokenIdx[i] = indexOf(tokenHeaders, mapping.TokenSourceName())
+		if dataIdx[i] == -1 || tokenIdx[i] == -1 {
+			return fmt.Errorf("column not found: data=%s token=%s", mapping.Source, mapping.TokenSourceName())
+		}
+	}
+
+	recordCount := 0
+	for {
+		if maxRecords > 0 && recordCount >= maxRecords {
+			break
+		}
+
+		dataRow, err := dataReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading data row: %w", err)
+		}
+
+		tokenRow, err := tokenReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading token row: %w", err)
+		}
+
+		fields := make([]Field, 0, len(vaultConfig.Columns))
+		for i, mapping := range vaultConfig.Columns {
+			if dataIdx[i] >= len(dataRow) || tokenIdx[i] >= len(tokenRow) {
+				continue
+			}
+			value := dataRow[dataIdx[i]]
+			token := tokenRow[tokenIdx[i]]
+			if value != "" && token != "" {
+				fields = append(fields, Field{
+					Column: mapping.Column,
+					Value:  strings.Clone(value),
+					Token:  strings.Clone(token),
+				})
+			}
+		}
+
+		if len(fields) > 0 {
+			emit(Record{Fields: fields})
+			recordCount++
+		}
+	}
+
+	return nil
+}
+
+// SnowflakeDataSource implements DataSource interface for Snowflake
+type SnowflakeDataSource struct {
+	Config SnowflakeConfig
+	DB     *sql.DB
+}
+
+// Connect establishes connection to Snowflake
+func (s *SnowflakeDataSource) Connect() error {
+	// Build DSN (Data Source Name) with URL encoding for special characters
+	// Format: user:password@account/database/schema?warehouse=wh&role=role&authenticator=type
+	dsn := fmt.Sprintf("%s:%s@%s/%s/%s?warehouse=%s&role=%s",
+		url.QueryEscape(s.Config.User),
+		url.QueryEscape(s.Config.Password),
+		s.Config.Account,
+		s.Config.Database,
+		s.Config.Schema,
+		url.QueryEscape(s.Config.Warehouse),
+		url.QueryEscape(s.Config.Role),
+	)
+
+	// Add authenticator if specified (e.g., "programmatic_access_token")
+	if s.Config.Authenticator != "" {
+		dsn += fmt.Sprintf("&authenticator=%s", url.QueryEscape(s.Config.Authenticator))
+	}
+
+	db, err := sql.Open("snowflake", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open Snowflake connection: %w", err)
+	}
+
+	// Configure connection pool
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(time.Hour)
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping Snowflake: %w", err)
+	}
+
+	s.DB = db
+	fmt.Println("✅ Successfully connected to Snowflake")
+	return nil
+}
+
+// Close closes the Snowflake connection
+func (s *SnowflakeDataSource) Close() error {
+	if s.DB != nil {
+		return s.DB.Close()
+	}
+	return nil
+}
+
+// buildSimpleQuery creates query for simple mode (single table with value + token columns)
+func (s *SnowflakeDataSource) buildSimpleQuery(vaultConfig VaultConfig) string {
+	// Build fully qualified table name
+	tableName := s.Config.SimpleTable
+	if tableName == "" {
+		// Default to ELEVANCE.PUBLIC.PATIENTS for backwards compatibility
+		tableName = "ELEVANCE.PUBLIC.PATIENTS"
+	}
+	// If table doesn't contain dots, prepend database.schema
+	if !strings.Contains(tableName, ".") {
+		tableName = fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, tableName)
+	}
+
+	var query string
+	switch vaultConfig.Column {
+	case "name":
+		query = fmt.Sprintf(`SELECT DISTINCT UPPER(full_name) AS full_name, full_name_token
+				FROM %s
+				WHERE full_name IS NOT NULL AND full_name_token IS NOT NULL`, tableName)
+	case "id":
+		query = fmt.Sprintf(`SELECT DISTINCT TO_VARCHAR(id) AS id, id_token
+				FROM %s
+				WHERE id IS NOT NULL AND id_token IS NOT NULL`, tableName)
+	case "dob":
+		query = fmt.Sprintf(`SELECT DISTINCT TO_VARCHAR(dob) AS dob, dob_token
+				FROM %s
+				WHERE dob IS NOT NULL AND dob_token IS NOT NULL`, tableName)
+	case "ssn":
+		query = fmt.Sprintf(`SELECT DISTINCT TO_VARCHAR(ssn) AS ssn, ssn_token
+				FROM %s
+				WHERE ssn IS NOT NULL AND ssn_token IS NOT NULL`, tableName)
+	}
+	return query
+}
+
+// buildMultiColumnQuery selects every mapped value/token column pair from the simple-mode table
+func (s *SnowflakeDataSource) buildMultiColumnQuery(vaultConfig VaultConfig) string {
+	tableName := s.Config.SimpleTable
+	if tableName == "" {
+		tableName = "ELEVANCE.PUBLIC.PATIENTS"
+	}
+	if !strings.Contains(tableName, ".") {
+		tableName = fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, tableName)
+	}
+
+	selects := make([]string, 0, len(vaultConfig.Columns)*2)
+	conditions := make([]string, 0, len(vaultConfig.Columns))
+	for _, mapping := range vaultConfig.Columns {
+		selects = append(selects,
+			fmt.Sprintf("TO_VARCHAR(%s) AS %s", mapping.Source, mapping.Source),
+			mapping.TokenSourceName())
+		conditions = append(conditions,
+			fmt.Sprintf("(%s IS NOT NULL AND %s IS NOT NULL)", mapping.Source, mapping.TokenSourceName()))
+	}
+
+	return fmt.Sprintf(`SELECT DISTINCT %s
+				FROM %s
+				WHERE %s`, strings.Join(selects, ", "), tableName, strings.Join(conditions, " OR "))
+}
+
+// buildGenericQuery creates query with UNIONs from configurable CLM and MBR tables
+func (s *SnowflakeDataSource) buildGenericQuery(vaultConfig VaultConfig) string {
+	// Build fully qualified table names
+	clmTable := fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, s.Config.CLMTable)
+	mbrTable := fmt.Sprintf("%s.%s.%s", s.Config.Database, s.Config.Schema, s.Config.MBRTable)
+
+	var query string
+	switch vaultConfig.Column {
+	case "name":
+		// First name, Last name, Middle initial from both CLM and MBR tables
+		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
+		query = fmt.Sprintf(`SELECT NAME, NAME_TOKEN
+FROM (
+	SELECT SRC_MBR_FRST_NM AS NAME, SRC_MBR_FRST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT FRST_NM AS NAME, FRST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SRC_MBR_LAST_NM AS NAME, SRC_MBR_LAST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT LAST_NM AS NAME, LAST_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SRC_MBR_MID_INIT_NM AS NAME, SRC_MBR_MID_INIT_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT MID_INIT_NM AS NAME, MID_INIT_NM_TOKEN AS NAME_TOKEN FROM %s GROUP BY 1, 2
+) DT
+GROUP BY 1, 2`, clmTable, mbrTable, clmTable, mbrTable, clmTable, mbrTable)
+	case "id":
+		// Multiple ID types from both CLM and MBR tables
+		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
+		query = fmt.Sprintf(`SELECT ID, ID_TOKEN
+FROM (
+	SELECT SRC_ENRLMNT_ID AS ID, SRC_ENRLMNT_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SRC_ENRLMNT_ID AS ID, SRC_ENRLMNT_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SRC_HC_ID AS ID, SRC_HC_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT HC_ID AS ID, HC_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SRC_SBSCRBR_ID AS ID, SRC_SBSCRBR_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SBSCRBR_ID AS ID, SBSCRBR_ID_TOKEN AS ID_TOKEN FROM %s GROUP BY 1, 2
+) DT
+GROUP BY 1, 2`, clmTable, mbrTable, clmTable, mbrTable, clmTable, mbrTable)
+	case "dob":
+		// Birth date from both CLM and MBR tables
+		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
+		query = fmt.Sprintf(`SELECT BRTH_DT, BRTH_DT_TOKEN
+FROM (
+	SELECT SRC_MBR_BRTH_DT AS BRTH_DT, SRC_MBR_BRTH_DT_TOKEN AS BRTH_DT_TOKEN FROM %s
+	UNION
+	SELECT BRTH_DT, BRTH_DT_TOKEN FROM %s
+) DT
+GROUP BY 1, 2`, clmTable, mbrTable)
+	case "ssn":
+		// SSN from both CLM and MBR tables
+		// Tokens are read directly from *_TOKEN columns (no UDF detokenization)
+		query = fmt.Sprintf(`SELECT SSN, SSN_TOKEN
+FROM (
+	SELECT SRC_MBR_SSN AS SSN, SRC_MBR_SSN_TOKEN AS SSN_TOKEN FROM %s GROUP BY 1, 2
+	UNION
+	SELECT SSN, SSN_TOKEN FROM %s GROUP BY 1, 2
+) DT
+GROUP BY 1, 2`, clmTable, mbrTable)
+	}
+	return query
+}
+
+// buildUnionQuery creates query for D01_SKYFLOW_POC with UNIONs and UDF detokenization
+func (s *SnowflakeDataSource) buildUnionQuery(vaultConfig VaultConfig) string {
+	var query string
+	switch vaultConfig.Column {
+	case "name":
+		// First name, Last name, Middle initial from both CLM and MBR tables
+		// Uses UDF detokenization on-the-fly
+		query = `SELECT NAME, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_MBR_NAME_DETOK(NAME) AS name_token
+FROM (
+	SELECT SRC_MBR_FRST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT FRST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+	UNION
+	SELECT SRC_MBR_LAST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT LAST_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+	UNION
+	SELECT SRC_MBR_MID_INIT_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT MID_INIT_NM AS NAME FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+) DT
+GROUP BY 1, 2`
+	case "id":
+		// Multiple ID types from both CLM and MBR tables
+		// Uses UDF detokenization on-the-fly
+		query = `SELECT ID, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_MBR_IDENTIFIERS_DETOK(ID) AS id_token
+FROM (
+	SELECT SRC_ENRLMNT_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT SRC_ENRLMNT_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+	UNION
+	SELECT SRC_HC_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT HC_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+	UNION
+	SELECT SRC_SBSCRBR_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT SBSCRBR_ID AS ID FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+) DT
+GROUP BY 1, 2`
+	case "dob":
+		// Birth date from both CLM and MBR tables
+		// Uses UDF detokenization on-the-fly
+		query = `SELECT BRTH_DT, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_BIRTHDATE_DETOK(BRTH_DT) AS dob_token
+FROM (
+	SELECT SRC_MBR_BRTH_DT AS BRTH_DT FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM
+	UNION
+	SELECT BRTH_DT FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR
+) DT
+GROUP BY 1, 2`
+	case "ssn":
+		// SSN from both CLM and MBR tables
+		// Uses UDF detokenization on-the-fly
+		query = `SELECT SSN, T01_PROTEGRITY.SCRTY_ACS_CNTRL.SKFL_SSN_DETOK(SSN) AS ssn_token
+FROM (
+	SELECT SRC_MBR_SSN AS SSN FROM D01_SKYFLOW_POC.SKYFLOW_POC.CLM GROUP BY 1
+	UNION
+	SELECT SSN FROM D01_SKYFLOW_POC.SKYFLOW_POC.MBR GROUP BY 1
+) DT
+GROUP BY 1, 2`
+	}
+	return query
+}
+
+// buildReadQuery picks the query for the configured mode and applies the record range
+func (s *SnowflakeDataSource) buildReadQuery(vaultConfig VaultConfig, maxRecords int) (string, error) {
+	var query string
+	switch {
+	case vaultConfig.IsMultiColumn():
+		// Multi-column rows come from a single table (union/generic modes produce one column per vault)
+		if s.Config.QueryMode == "union" || s.Config.QueryMode == "generic" {
+			return "", fmt.Errorf("multi-column vaults require simple query mode (got %s)", s.Config.QueryMode)
+		}
+		query = s.buildMultiColumnQuery(vaultConfig)
+	case s.Config.QueryMode == "union":
+		// D01_SKYFLOW_POC mode: UNION queries with UDF detokenization (hardcoded db/table names)
+		query = s.buildUnionQuery(vaultConfig)
+	case s.Config.QueryMode == "generic":
+		// Generic mode: UNION queries with configurable db/table names (reads *_TOKEN columns directly)
+		query = s.buildGenericQuery(vaultConfig)
+	default:
+		// Simple mode (default): ELEVANCE.PUBLIC.PATIENTS table
+		query = s.buildSimpleQuery(vaultConfig)
+	}
+
+	// Add LIMIT and OFFSET for manual chunking
+	// Priority: start-record/end-record flags > max-records flag
+	// NOTE: Snowflake syntax requires LIMIT before OFFSET
+	if s.Config.StartRecord > 0 || s.Config.EndRecord > 0 {
+		// Manual chunking mode
+		if s.Config.EndRecord > 0 {
+			limit := s.Config.EndRecord - s.Config.StartRecord
+			query += fmt.Sprintf(" LIMIT %d", limit)
+		} else if maxRecords > 0 {
+			query += fmt.Sprintf(" LIMIT %d", maxRecords)
+		}
+		if s.Config.StartRecord > 0 {
+			query += fmt.Sprintf(" OFFSET %d", s.Config.StartRecord)
+		}
+	} else if maxRecords > 0 {
+		// Simple max records mode
+		query += fmt.Sprintf(" LIMIT %d", maxRecords)
+	}
+	return query, nil
+}
+
+// newRowScanner returns a function that scans one query row into a Record
+func newRowScanner(vaultConfig VaultConfig) func(rows *sql.Rows) (Record, error) {
+	if !vaultConfig.IsMultiColumn() {
+		return func(rows *sql.Rows) (Record, error) {
+			var record Record
+			if err := rows.Scan(&record.Value, &record.Token); err != nil {
+				return Record{}, fmt.Errorf("failed to scan row: %w", err)
+			}
+			return record, nil
+		}
+	}
+
+	// Multi-column rows scan one value/token pair per mapped column
+	columnValues := make([]sql.NullString, len(vaultConfig.Columns)*2)
+	scanTargets := make([]interface{}, len(columnValues))
+	for i := range columnValues {
+		scanTargets[i] = &columnValues[i]
+	}
+	return func(rows *sql.Rows) (Record, error) {
+		var record Record
+		if err := rows.Scan(scanTargets...); err != nil {
+			return Record{}, fmt.Errorf("failed to scan row: %w", err)
+		}
+		for i, mapping := range vaultConfig.Columns {
+			value, token := columnValues[i*2], columnValues[i*2+1]
+			if value.Valid && token.Valid && value.String != "" && token.String != "" {
+				record.Fields = append(record.Fields, Field{
+					Column: mapping.Column,
+					Value:  value.String,
+					Token:  token.String,
+				})
+			}
+		}
+		return record, nil
+	}
+}
+
+// SampleRecords draws the sample inside Snowflake (SAMPLE n ROWS over the load query), so only
+// sampleSize rows cross the network. The source size isn't counted (-1) to avoid a second scan.
+func (s *SnowflakeDataSource) SampleRecords(vaultConfig VaultConfig, maxRecords, sampleSize int) ([]Record, int, error) {
+	if sampleSize <= 0 {
+		records, err := s.ReadRecords(vaultConfig, maxRecords)
+		return records, len(records), err
+	}
+	if s.DB == nil {
+		return nil, 0, fmt.Errorf("no active Snowflake connection")
+	}
+	query, err := s.buildReadQuery(vaultConfig, maxRecords)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.DB.Query(fmt.Sprintf("SELECT * FROM (%s) SAMPLE (%d ROWS)", query, sampleSize))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to execute sample query: %w", err)
+	}
+	defer rows.Close()
+
+	scan := newRowScanner(vaultConfig)
+	records := make([]Record, 0, sampleSize)
+	for rows.Next() {
+		record, err := scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		if (record.Value != "" && record.Token != "") || len(record.Fields) > 0 {
+			records = append(records, record)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating sample rows: %w", err)
+	}
+	return records, -1, nil
+}
+
+// ReadRecords reads records from Snowflake using cursor-based fetching
+// The query executes ONCE on Snowflake, then rows are fetched incrementally via cursor
+// The Snowflake driver automatically batches network fetches based on fetch_size
+func (s *SnowflakeDataSource) ReadRecords(vaultConfig VaultConfig, maxRecords int) ([]Record, error) {
 	if s.DB == nil {
 		return nil, fmt.Errorf("no active Snowflake connection")
 	}
+	query, err := s.buildReadQuery(vaultConfig, maxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configure fetch size for this session if specified
+	// This controls how many rows are fetched from Snowflake to the client in each network round trip
+	if s.Config.FetchSize > 0 {
+		if s.Config.StartRecord > 0 || s.Config.EndRecord > 0 {
+			fmt.Printf("📊 Querying Snowflake for %s data (records %d-%d, network fetch size %d)...\n",
+				vaultConfig.Name, s.Config.StartRecord, s.Config.EndRecord, s.Config.FetchSize)
+		} else {
+			fmt.Printf("📊 Querying Snowflake for %s data (cursor mode: network fetch size %d)...\n",
+				vaultConfig.Name, s.Config.FetchSize)
+		}
+		// Set session parameter for fetch size
+		_, err := s.DB.Exec(fmt.Sprintf("ALTER SESSION SET ROWS_PER_RESULTSET = %d", s.Config.FetchSize))
+		if err != nil {
+			fmt.Printf("⚠️  Warning: Could not set fetch size: %v (continuing anyway)\n", err)
+		}
+	} else {
+		if s.Config.StartRecord > 0 || s.Config.EndRecord > 0 {
+			fmt.Printf("📊 Querying Snowflake for %s data (records %d-%d)...\n",
+				vaultConfig.Name, s.Config.StartRecord, s.Config.EndRecord)
+		} else {
+			fmt.Printf("📊 Querying Snowflake for %s data...\n", vaultConfig.Name)
+		}
+	}
+
+	// Execute query ONCE - this creates a cursor on Snowflake
+	fmt.Printf("  🔍 Executing SQL query on Snowflake...\n")
+	queryStart := time.Now()
+	rows, err := s.DB.Query(query)
+	queryDuration := time.Since(queryStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	fmt.Printf("  ✅ Query executed successfully (%.2f seconds)\n", queryDuration.Seconds())
+
+	// Pre-allocate slice
+	capacity := maxRecords
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	records := make([]Record, 0, capacity)
+
+	// Stream results via cursor - the driver fetches in batches behind the scenes
+	fmt.Printf("  📥 Starting to fetch rows from result set...\n")
+	recordCount := 0
+	lastLog := time.Now()
+
+	scan := newRowScanner(vaultConfig)
+	for rows.Next() {
+		record, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if (record.Value != "" && record.Token != "") || len(record.Fields) > 0 {
+			records = append(records, record)
+			recordCount++
+
+			// Progress update - more frequent for smaller datasets
+			progressInterval := 100000
+			if maxRecords > 0 && maxRecords < 100000 {
+				progressInterval = maxRecords / 10 // Log every 10%
+				if progressInterval < 100 {
+					progressInterval = 100 // At least every 100 records
+				}
+			}
+
+			// Also log every 5 seconds regardless of record count
+			if recordCount%progressInterval == 0 || time.Since(lastLog) > 5*time.Second {
+				fmt.Printf("  📥 Fetched %d records from Snowflake so far...\n", recordCount)
+				lastLog = time.Now()
+			}
+		}
+
+		if maxRecords > 0 && recordCount >= maxRecords {
+			break
+		}
+	}
+	fmt.Printf("  ✅ Finished fetching rows from Snowflake\n")
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	fmt.Printf("✅ Retrieved %d records from Snowflake\n", len(records))
+	return records, nil
+}
+
+func getDataColumnName(column string) string {
+	switch column {
+	case "name":
+		return "full_name"
+	case "id":
+		return "id"
+	case "dob":
+		return "dob"
+	case "ssn":
+		return "ssn"
+	default:
+		return column
+	}
+}
+
+func getTokenColumnName(column string) string {
+	return getDataColumnName(column) + "_token"
+}
+
+// Create BYOT payload (optimized with buffer pool)
+func createBYOTPayload(records []Record, vaultConfig VaultConfig, config *Config, metrics *Metrics) ([]byte, error) {
+	payloadStart := time.Now()
+
+	// Stream the payload straight into a pooled buffer. Single-column rows produce the same bytes
+	// as the old map-based encoding/json payload; multi-column fields are written in config order
+	// rather than sorted (the vault does not depend on key order).
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+	buf.Grow(len(records) * 128)
+
+	// json_serialization is the encode itself; payload_creation is the buffer setup and the
+	// copy out of the pool
+	jsonStart := time.Now()
+	var suffix [96]byte
+	writeString := func(value string) {
+		buf.WriteByte('"')
+		writeJSONStringContent(buf, value)
+		if config.AppendSuffix {
+			suffixStart := time.Now()
+			buf.WriteByte('_')
+			buf.Write(config.Suffix.Append(suffix[:0], value)) // Suffixes are JSON-safe ([A-Za-z0-9_-])
+			metrics.AddTime("suffix_gen", time.Since(suffixStart))
+		}
+		buf.WriteByte('"')
+	}
+	writeKey := func(key string) {
+		buf.WriteByte('"')
+		writeJSONStringContent(buf, key)
+		buf.WriteString(`":`)
+	}
+
+	buf.WriteString(`{"byot":"ENABLE","continueOnError":true,"records":[`)
+	for i := range records {
+		record := &records[i]
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.WriteString(`{"fields":{`)
+		if len(record.Fields) > 0 {
+			// Multi-column row: every mapped column goes into the same vault record
+			for j, field := range record.Fields {
+				if j > 0 {
+					buf.WriteByte(',')
+				}
+				writeKey(field.Column)
+				writeString(field.Value)
+			}
+		} else {
+			writeKey(vaultConfig.Column)
+			writeString(record.Value)
+		}
+
+		buf.WriteString(`},"tokens":{`)
+		if len(record.Fields) > 0 {
+			for j, field := range record.Fields {
+				if j > 0 {
+					buf.WriteByte(',')
+				}
+				writeKey(field.Column)
+				writeString(field.Token)
+			}
+		} else {
+			writeKey(vaultConfig.Column)
+			writeString(record.Token)
+		}
+		buf.WriteString(`}}`)
+	}
+	buf.WriteString(`],"tokenization":true`)
+
+	// Add upsert parameter if enabled (upsert on the column being inserted, or the
+	// configured key column for multi-column rows)
+	if config.Upsert {
+		buf.WriteByte(',')
+		writeKey("upsert")
+		buf.WriteByte('"')
+		writeJSONStringContent(buf, vaultConfig.Column)
+		buf.WriteByte('"')
+	}
+	buf.WriteString("}\n")
+	encodeTime := time.Since(jsonStart)
+	metrics.AddTime("json_serialization", encodeTime)
+
+	// Copy buffer content out of the pool (the only allocation per batch)
+	jsonData := make([]byte, buf.Len())
+	copy(jsonData, buf.Bytes())
+	metrics.AddTime("payload_creation", time.Since(payloadStart)-encodeTime)
+
+	return jsonData, nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeJSONStringContent writes s as the body of a JSON string literal, escaping it exactly
+// like encoding/json: control characters, quotes, backslashes, the HTML-sensitive <, > and &,
+// U+2028/U+2029, and invalid UTF-8 (replaced with U+FFFD)
+func writeJSONStringContent(buf *bytes.Buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+}
+
+// PayloadSink writes BYOT payloads to an NDJSON file instead of sending them (dry-run mode).
+// Safe for concurrent workers; optionally gzip-compressed and with values/tokens redacted.
+type PayloadSink struct {
+	Path     string
+	Redact   bool
+	mu       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	writer   *bufio.Writer
+	Payloads int64
+	Bytes    int64 // Uncompressed payload bytes (what would have been sent)
+}
+
+// NewPayloadSink creates the dry-run output file (.gz is appended when compressing)
+func NewPayloadSink(path string, compress, redact bool) (*PayloadSink, error) {
+	if compress && !strings.HasSuffix(path, ".gz") {
+		path += ".gz"
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dry-run output file: %w", err)
+	}
+
+	sink := &PayloadSink{Path: path, Redact: redact, file: file}
+	if compress {
+		sink.gz = gzip.NewWriter(file)
+		sink.writer = bufio.NewWriterSize(sink.gz, 256*1024)
+	} else {
+		sink.writer = bufio.NewWriterSize(file, 256*1024)
+	}
+	return sink, nil
+}
+
+// Write appends one line: {"vault":..,"batch":..,"url":..,"payload":{..}}
+func (s *PayloadSink) Write(vaultName string, batchNum int, apiURL string, payload []byte) error {
+	payload = bytes.TrimRight(payload, "\n")
+	if s.Redact {
+		redacted, err := redactPayload(payload)
+		if err != nil {
+			return err
+		}
+		payload = redacted
+	}
+
+	line, err := json.Marshal(struct {
+		Vault   string          `json:"vault"`
+		Batch   int             `json:"batch"`
+		URL     string          `json:"url"`
+		Payload json.RawMessage `json:"payload"`
+	}{vaultName, batchNum, apiURL, payload})
+	if err != nil {
+		return fmt.Errorf("failed to encode dry-run line: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.writer.Write(line); err != nil {
+		return fmt.Errorf("failed to write dry-run payload: %w", err)
+	}
+	if err := s.writer.WriteByte('\n'); err != nil {
+		return fmt.Errorf("failed to write dry-run payload: %w", err)
+	}
+	s.Payloads++
+	s.Bytes += int64(len(payload))
+	return nil
+}
+
+// Close flushes buffered payloads and closes the file
+func (s *PayloadSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if s.gz != nil {
+		if err := s.gz.Close(); err != nil {
+			return err
+		}
+	}
+	return s.file.Close()
+}
+
+// CrosswalkEntry maps one inserted value (by hash) to its token and skyflow_id
+type CrosswalkEntry struct {
+	Vault     string `json:"vault"`
+	Batch     int    `json:"batch"`
+	Column    string `json:"column"`
+	ValueHash string `json:"value_hmac"` // HMAC-SHA256 of the source value (before any suffix) with the crosswalk key
+	Token     string `json:"token"`
+	SkyflowID string `json:"skyflow_id"`
+}
+
+// CrosswalkSink records the skyflow_id of every inserted record to a CSV/NDJSON file or a
+// Snowflake table; safe for concurrent workers. File entries are written per batch as responses
+// arrive. Snowflake entries are queued to a single writer goroutine that inserts them in large
+// chunks, so batches never wait on a warehouse round trip.
+type CrosswalkSink struct {
+	Target    string // File path or Snowflake table name
+	Format    string // "csv", "ndjson" or "snowflake"
+	mu        sync.Mutex
+	file      *os.File
+	writer    *bufio.Writer
+	csv       *csv.Writer
+	queue     chan []CrosswalkEntry        // Batches waiting for the writer goroutine (queued sinks only)
+	insert    func([]CrosswalkEntry) error // Writes one chunk (queued sinks only)
+	done      chan struct{}                // Closed when the writer goroutine has drained the queue
+	closed    bool
+	closeOnce sync.Once
+	closeErr  error
+	hmacs     sync.Pool // HMAC-SHA256 keyed with the crosswalk key
+	Entries   int64
+	Failed    int64 // Entries that could not be written
+}
+
+var crosswalkColumns = []string{"vault", "batch", "column", "value_hmac", "token", "skyflow_id"}
+
+const (
+	crosswalkKeyEnv    = "SKYFLOW_CROSSWALK_HASH_KEY"
+	minCrosswalkKeyLen = 16
+
+	crosswalkChunkRows     = 100000          // Rows per Snowflake INSERT (bound as arrays, so the driver stages them)
+	crosswalkQueueBatches  = 1024            // Batches queued for the writer before workers wait
+	crosswalkFlushInterval = 5 * time.Second // Longest a partial chunk waits before it is inserted
+)
+
+// crosswalkKey returns the crosswalk HMAC key from the environment or the config file
+func crosswalkKey(configKey string) ([]byte, error) {
+	key := cmp.Or(os.Getenv(crosswalkKeyEnv), configKey)
+	if key == "" {
+		return nil, fmt.Errorf("the crosswalk needs a hash key: set %s or skyflow.crosswalk_hash_key (keep it to join on value hashes)", crosswalkKeyEnv)
+	}
+	if len(key) < minCrosswalkKeyLen {
+		return nil, fmt.Errorf("crosswalk hash key must be at least %d characters", minCrosswalkKeyLen)
+	}
+	return []byte(key), nil
+}
+
+// setKey keys the sink's value hashes
+func (s *CrosswalkSink) setKey(key []byte) {
+	s.hmacs.New = func() interface{} { return hmac.New(sha256.New, key) }
+}
+
+// HashValue returns the hex HMAC-SHA256 of a source value. Unlike a plain hash, it cannot be
+// reversed by hashing every possible SSN or date of birth without the key.
+func (s *CrosswalkSink) HashValue(value string) string {
+	mac := s.hmacs.Get().(hash.Hash)
+	defer s.hmacs.Put(mac)
+	mac.Reset()
+	mac.Write([]byte(value))
+	var sum [sha256.Size]byte
+	return hex.EncodeToString(mac.Sum(sum[:0]))
+}
+
+var snowflakeTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*){0,2}$`)
+
+// NewFileCrosswalkSink creates a CSV or NDJSON crosswalk file (format "" picks it from the extension)
+func NewFileCrosswalkSink(path, format string, key []byte) (*CrosswalkSink, error) {
+	if format == "" {
+		format = "csv"
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".ndjson" || ext == ".jsonl" {
+			format = "ndjson"
+		}
+	}
+	if format != "csv" && format != "ndjson" {
+		return nil, fmt.Errorf("unsupported crosswalk format %q (use csv or ndjson)", format)
+	}
+
+	// Tokens and skyflow_ids are sensitive, so keep the file private like the source data
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create crosswalk file: %w", err)
+	}
+
+	sink := &CrosswalkSink{Target: path, Format: format, file: file, writer: bufio.NewWriterSize(file, 256*1024)}
+	sink.setKey(key)
+	if format == "csv" {
+		sink.csv = csv.NewWriter(sink.writer)
+		if err := sink.csv.Write(crosswalkColumns); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write crosswalk header: %w", err)
+		}
+	}
+	return sink, nil
+}
+
+// NewSnowflakeCrosswalkSink creates the crosswalk table if needed and inserts into it
+func NewSnowflakeCrosswalkSink(db *sql.DB, table string, key []byte) (*CrosswalkSink, error) {
+	if !snowflakeTablePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid crosswalk table name %q (use TABLE, SCHEMA.TABLE or DATABASE.SCHEMA.TABLE)", table)
+	}
+	createSQL := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		VAULT STRING, BATCH_NUMBER NUMBER, COLUMN_NAME STRING, VALUE_HMAC STRING,
+		TOKEN STRING, SKYFLOW_ID STRING, LOADED_AT TIMESTAMP_NTZ DEFAULT CURRENT_TIMESTAMP())`, table)
+	if _, err := db.Exec(createSQL); err != nil {
+		return nil, fmt.Errorf("failed to create crosswalk table %s: %w", table, err)
+	}
+	insertSQL := fmt.Sprintf("INSERT INTO %s (VAULT, BATCH_NUMBER, COLUMN_NAME, VALUE_HMAC, TOKEN, SKYFLOW_ID) VALUES (?, ?, ?, ?, ?, ?)", table)
+	insert := func(entries []CrosswalkEntry) error {
+		// Array binding: one statement per chunk, which the driver uploads to a temporary
+		// stage and loads in bulk once it is large enough
+		vaults := make([]string, len(entries))
+		batches := make([]int, len(entries))
+		columns := make([]string, len(entries))
+		hashes := make([]string, len(entries))
+		tokens := make([]string, len(entries))
+		ids := make([]string, len(entries))
+		for i, e := range entries {
+			vaults[i], batches[i], columns[i], hashes[i], tokens[i], ids[i] = e.Vault, e.Batch, e.Column, e.ValueHash, e.Token, e.SkyflowID
+		}
+		_, err := db.Exec(insertSQL, gosnowflake.Array(vaults), gosnowflake.Array(batches), gosnowflake.Array(columns),
+			gosnowflake.Array(hashes), gosnowflake.Array(tokens), gosnowflake.Array(ids))
+		if err != nil {
+			return fmt.Errorf("failed to insert crosswalk rows: %w", err)
+		}
+		return nil
+	}
+	return newQueuedCrosswalkSink(table, "snowflake", key, insert), nil
+}
+
+// newQueuedCrosswalkSink starts the writer goroutine that collects queued batches into chunks
+// of up to crosswalkChunkRows and hands them to insert
+func newQueuedCrosswalkSink(target, format string, key []byte, insert func([]CrosswalkEntry) error) *CrosswalkSink {
+	sink := &CrosswalkSink{Target: target, Format: format, insert: insert,
+		queue: make(chan []CrosswalkEntry, crosswalkQueueBatches), done: make(chan struct{})}
+	sink.setKey(key)
+	go sink.runWriter()
+	return sink
+}
+
+// runWriter inserts queued entries in chunks: when a chunk fills, every crosswalkFlushInterval,
+// and once more when the queue is closed
+func (s *CrosswalkSink) runWriter() {
+	defer close(s.done)
+	ticker := time.NewTicker(crosswalkFlushInterval)
+	defer ticker.Stop()
+
+	pending := make([]CrosswalkEntry, 0, crosswalkChunkRows)
+	flush := func() {
+		for start := 0; start < len(pending); start += crosswalkChunkRows {
+			chunk := pending[start:min(start+crosswalkChunkRows, len(pending))]
+			if err := s.insert(chunk); err != nil {
+				atomic.AddInt64(&s.Failed, int64(len(chunk)))
+				s.closeErr = err
+				logger.Warn(fmt.Sprintf("Failed to record %d skyflow_id crosswalk entries: %v", len(chunk), err), "component", "crosswalk", "error", err)
+				continue
+			}
+			atomic.AddInt64(&s.Entries, int64(len(chunk)))
+		}
+		pending = pending[:0]
+	}
+
+	for {
+		select {
+		case entries, ok := <-s.queue:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, entries...)
+			if len(pending) >= crosswalkChunkRows {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Write appends one batch of entries. Queued sinks count entries once the writer has inserted
+// them; insert failures are logged by the writer and returned by Close.
+func (s *CrosswalkSink) Write(entries []CrosswalkEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if s.queue != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.closed {
+			atomic.AddInt64(&s.Failed, int64(len(entries)))
+			return fmt.Errorf("crosswalk %s is already closed", s.Target)
+		}
+		s.queue <- entries
+		return nil
+	}
+	err := s.write(entries)
+	if err != nil {
+		atomic.AddInt64(&s.Failed, int64(len(entries)))
+		return err
+	}
+	atomic.AddInt64(&s.Entries, int64(len(entries)))
+	return nil
+}
+
+func (s *CrosswalkSink) write(entries []CrosswalkEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range entries {
+		if s.csv != nil {
+			if err := s.csv.Write([]string{e.Vault, strconv.Itoa(e.Batch), e.Column, e.ValueHash, e.Token, e.SkyflowID}); err != nil {
+				return fmt.Errorf("failed to write crosswalk entry: %w", err)
+			}
+			continue
+		}
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode crosswalk entry: %w", err)
+		}
+		s.writer.Write(line)
+		s.writer.WriteByte('\n')
+	}
+
+	// Flush every batch so the file is usable while the load is still running
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return fmt.Errorf("failed to write crosswalk entries: %w", err)
+		}
+	}
+	if err := s.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write crosswalk entries: %w", err)
+	}
+	return nil
+}
+
+// Close inserts every queued entry, or flushes and closes the crosswalk file (the Snowflake
+// connection is owned by the caller). Safe to call more than once.
+func (s *CrosswalkSink) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		if s.queue != nil {
+			close(s.queue)
+			s.mu.Unlock()
+			<-s.done // closeErr is only written by the writer, which has exited
+			return
+		}
+		defer s.mu.Unlock()
+		if s.file == nil {
+			return
+		}
+		if err := s.writer.Flush(); err != nil {
+			s.file.Close()
+			s.closeErr = err
+			return
+		}
+		s.closeErr = s.file.Close()
+	})
+	return s.closeErr
+}
+
+// InsertResponse is the vault's answer to a BYOT insert
+type InsertResponse struct {
+	Records []struct {
+		SkyflowID    string            `json:"skyflow_id"`
+		Tokens       map[string]string `json:"tokens"`
+		RequestIndex *int              `json:"request_index"`
+		Error        json.RawMessage   `json:"error"`
+	} `json:"records"`
+}
+
+// rejectedRecords returns the records a successful continueOnError insert response reports as
+// failed, with the first record error's HTTP code (0 when not reported) and message. Responses
+// without per-record errors are not parsed further than a substring check.
+func rejectedRecords(batch []Record, body []byte) ([]Record, int, string) {
+	if !bytes.Contains(body, []byte(`"error"`)) {
+		return nil, 0, ""
+	}
+	var resp InsertResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, 0, ""
+	}
+	var rejected []Record
+	status, message := 0, ""
+	for i, record := range resp.Records {
+		if len(record.Error) == 0 || string(record.Error) == "null" {
+			continue
+		}
+		index := i
+		if record.RequestIndex != nil {
+			index = *record.RequestIndex
+		}
+		if index < 0 || index >= len(batch) {
+			continue
+		}
+		if len(rejected) == 0 {
+			status, message = parseRecordError(record.Error)
+		}
+		rejected = append(rejected, batch[index])
+	}
+	return rejected, status, message
+}
+
+// parseRecordError reads the code and message of a per-record error, which the vault sends as
+// an object ({"code"/"http_code", "message"/"description"}) or a plain string
+func parseRecordError(raw json.RawMessage) (int, string) {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return 0, text
+	}
+	var e struct {
+		Code        json.Number `json:"code"`
+		HTTPCode    json.Number `json:"http_code"`
+		Message     string      `json:"message"`
+		Description string      `json:"description"`
+	}
+	if json.Unmarshal(raw, &e) != nil {
+		return 0, string(raw)
+	}
+	status, err := e.HTTPCode.Int64()
+	if err != nil {
+		status, _ = e.Code.Int64()
+	}
+	return int(status), cmp.Or(e.Message, e.Description, string(raw))
+}
+
+// buildCrosswalkEntries pairs each record of a successful batch with the skyflow_id from the
+// insert response. Records the vault rejected (continueOnError) have no skyflow_id and are skipped.
+func buildCrosswalkEntries(vaultConfig VaultConfig, batchNum int, batch []Record, body []byte, hashValue func(string) string) ([]CrosswalkEntry, error) {
+	var resp InsertResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse insert response: %w", err)
+	}
+
+	entries := make([]CrosswalkEntry, 0, len(batch))
+	for i, record := range resp.Records {
+		index := i
+		if record.RequestIndex != nil {
+			index = *record.RequestIndex
+		}
+		if record.SkyflowID == "" || index < 0 || index >= len(batch) ||
+			(len(record.Error) > 0 && string(record.Error) != "null") {
+			continue
+		}
+
+		// Prefer the token the vault reports (it includes any -append-suffix suffix)
+		addEntry := func(column, value, token string) {
+			if vaultToken := record.Tokens[column]; vaultToken != "" {
+				token = vaultToken
+			}
+			entries = append(entries, CrosswalkEntry{
+				Vault:     vaultConfig.Name,
+				Batch:     batchNum,
+				Column:    column,
+				ValueHash: hashValue(value),
+				Token:     token,
+				SkyflowID: record.SkyflowID,
+			})
+		}
+
+		source := batch[index]
+		if len(source.Fields) > 0 {
+			for _, field := range source.Fields {
+				addEntry(field.Column, field.Value, field.Token)
+			}
+		} else {
+			addEntry(vaultConfig.Column, source.Value, source.Token)
+		}
+	}
+	return entries, nil
+}
+
+// redactPayload replaces every field value and token with a length-only placeholder
+func redactPayload(payload []byte) ([]byte, error) {
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("failed to parse payload for redaction: %w", err)
+	}
+	records, _ := body["records"].([]interface{})
+	for _, r := range records {
+		record, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"fields", "tokens"} {
+			values, ok := record[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for column, v := range values {
+				str, _ := v.(string)
+				values[column] = fmt.Sprintf("[REDACTED len=%d]", len(str))
+			}
+		}
+	}
+	return json.Marshal(body)
+}
+
+// estimateRuntime approximates wall-clock time for a real load
+// Assumes ~200ms per batch average (including retries) plus the base delay, spread across workers
+func estimateRuntime(numBatches int, config *Config) time.Duration {
+	if config.MaxConcurrency <= 0 {
+		return 0
+	}
+	perBatch := 200*time.Millisecond + config.BaseRequestDelay
+	return time.Duration(int64(numBatches) * int64(perBatch) / int64(config.MaxConcurrency))
+}
+
+// formatEstimate renders an estimated duration in seconds, minutes or hours
+func formatEstimate(d time.Duration) string {
+	seconds := d.Seconds()
+	if seconds < 60 {
+		return fmt.Sprintf("~%.0f seconds", seconds)
+	} else if seconds < 3600 {
+		return fmt.Sprintf("~%.1f minutes", seconds/60)
+	}
+	return fmt.Sprintf("~%.1f hours", seconds/3600)
+}
+
+// RequestCompressor compresses BYOT request bodies with gzip or zstd. If the server rejects
+// compressed bodies it is switched off for the rest of the run and batches go out uncompressed.
+type RequestCompressor struct {
+	Encoding string // "gzip" or "zstd" (Content-Encoding value)
+	Level    int
+	disabled int32
+	gzipPool sync.Pool
+	zstd     *zstd.Encoder
+}
+
+// NewRequestCompressor validates the encoding and level (0 = library default).
+// gzip levels are 1-9; zstd levels are 1-22 and map onto the encoder's speed presets.
+func NewRequestCompressor(encoding string, level int) (*RequestCompressor, error) {
+	c := &RequestCompressor{Encoding: encoding, Level: level}
+
+	switch encoding {
+	case "gzip":
+		if level == 0 {
+			c.Level = gzip.DefaultCompression
+		} else if level < gzip.BestSpeed || level > gzip.BestCompression {
+			return nil, fmt.Errorf("gzip compression level must be 1-9, got %d", level)
+		}
+		c.gzipPool.New = func() interface{} {
+			w, _ := gzip.NewWriterLevel(io.Discard, c.Level)
+			return w
+		}
+	case "zstd":
+		encoderLevel := zstd.SpeedDefault
+		if level != 0 {
+			if level < 1 || level > 22 {
+				return nil, fmt.Errorf("zstd compression level must be 1-22, got %d", level)
+			}
+			encoderLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		// EncodeAll is safe for concurrent use, so one encoder serves every worker
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		c.zstd = encoder
+	default:
+		return nil, fmt.Errorf("unsupported request compression %q (use gzip or zstd)", encoding)
+	}
+	return c, nil
+}
+
+// Enabled reports whether bodies should still be compressed
+func (c *RequestCompressor) Enabled() bool {
+	return c != nil && atomic.LoadInt32(&c.disabled) == 0
+}
+
+// Disable turns compression off for the rest of the run. Only the first caller gets true,
+// so the fallback is logged once.
+func (c *RequestCompressor) Disable() bool {
+	return atomic.CompareAndSwapInt32(&c.disabled, 0, 1)
+}
+
+// Compress returns the encoded payload
+func (c *RequestCompressor) Compress(payload []byte) ([]byte, error) {
+	if c.zstd != nil {
+		return c.zstd.EncodeAll(payload, make([]byte, 0, len(payload)/4)), nil
+	}
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufferPool.Put(buf)
+
+	gw := c.gzipPool.Get().(*gzip.Writer)
+	defer c.gzipPool.Put(gw)
+	gw.Reset(buf)
+	if _, err := gw.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to gzip payload: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to gzip payload: %w", err)
+	}
+
+	compressed := make([]byte, buf.Len())
+	copy(compressed, buf.Bytes())
+	return compressed, nil
+}
+
+// rejectsCompression reports whether a response means the server can't read a compressed body:
+// 415 Unsupported Media Type, or a 400 whose error code or message is about the Content-Encoding
+// header. Other 400s (e.g. a value with a bad character encoding) keep compression on.
+func rejectsCompression(statusCode int, body []byte) bool {
+	if statusCode == http.StatusUnsupportedMediaType {
+		return true
+	}
+	if statusCode != http.StatusBadRequest {
+		return false
+	}
+	detail := string(body)
+	var resp struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil && len(resp.Error) > 0 {
+		_, detail = parseRecordError(resp.Error)
+	}
+	detail = strings.ToLower(detail)
+	return strings.Contains(detail, "content-encoding") || strings.Contains(detail, "content_encoding")
+}
+
+// Send batch to Skyflow (optimized with shared HTTP client)
+func sendBatch(client *http.Client, config *Config, vaultConfig VaultConfig, apiURL string, batch []Record, batchNum int, metrics *Metrics) error {
+	span := tracer.StartSampled(metrics.Trace, "batch",
+		attribute.String("vault.name", vaultConfig.Name), attribute.Int("batch.number", batchNum), attribute.Int("batch.records", len(batch)))
+	err := sendBatchAttempts(client, config, vaultConfig, apiURL, batch, batchNum, metrics, span)
+	if err != nil {
+		span.SetAttributes(attribute.String("batch.outcome", "failed"))
+		setSpanError(span, err.Error())
+	} else {
+		span.SetAttributes(attribute.String("batch.outcome", "success"))
+	}
+	span.End()
+	return err
+}
+
+// sendBatchAttempts builds, sends and retries one batch; span (nil when not traced) gets a child
+// span per stage and HTTP attempt
+func sendBatchAttempts(client *http.Client, config *Config, vaultConfig VaultConfig, apiURL string, batch []Record, batchNum int, metrics *Metrics, span trace.Span) error {
+
+	// Base delay (skipped in dry-run; accounted for in the runtime estimate instead)
+	if delay := controller.BaseDelay(config.BaseRequestDelay); delay > 0 && config.DryRun == nil {
+		delayStart := time.Now()
+		time.Sleep(delay)
+		metrics.AddTime("base_delay", time.Since(delayStart))
+		tracer.Record(span, "base_delay", delayStart)
+	}
+
+	// Create payload
+	payloadStart := time.Now()
+	payload, err := createBYOTPayload(batch, vaultConfig, config, metrics)
+	if err != nil {
+		return fmt.Errorf("failed to create payload: %w", err)
+	}
+	tracer.Record(span, "payload.build", payloadStart, attribute.Int("payload.bytes", len(payload)))
+
+	// Dry-run: record the payload exactly as it would be sent and count the batch as successful
+	if config.DryRun != nil {
+		if err := config.DryRun.Write(vaultConfig.Name, batchNum, apiURL, payload); err != nil {
+			metrics.AddFailedBatch()
+			return err
+		}
+		atomic.AddInt64(&metrics.ImmediateSuccesses, 1)
+		metrics.AddSuccessfulBatch()
+		metrics.AddRecords(len(batch))
+		return nil
+	}
+
+	// Compress once; every attempt reuses the same body unless the server rejects the encoding
+	var compressed []byte
+	if config.Compressor.Enabled() {
+		compressStart := time.Now()
+		compressed, err = config.Compressor.Compress(payload)
+		if err != nil {
+			metrics.AddFailedBatch()
+			return err
+		}
+		metrics.AddTime("compression", time.Since(compressStart))
+		tracer.Record(span, "compress", compressStart,
+			attribute.String("compression.encoding", config.Compressor.Encoding), attribute.Int("payload.compressed_bytes", len(compressed)))
+	}
+
+	blog := logger.With("component", "sender", "vault", vaultConfig.Name, "batch", batchNum)
+
+	// Retry logic with exponential backoff
+	maxRetries := 3
+	hadRetry := false
+	authRetried := false
+	compressionRetried := false
+	attemptsSent := 0
+	defer func() { span.SetAttributes(attribute.Int("batch.attempts", attemptsSent)) }()
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		bearerToken, err := config.Auth.Token()
+		if err != nil {
+			metrics.AddFailedBatch()
+			return fmt.Errorf("failed to obtain bearer token: %w", err)
+		}
+
+		body := payload
+		useCompression := compressed != nil && config.Compressor.Enabled()
+		if useCompression {
+			body = compressed
+		}
+
+		// The batch span in the request context parents the attempt's HTTP client span (see Tracer.Transport)
+		req, err := http.NewRequestWithContext(trace.ContextWithSpan(context.Background(), span), "POST", apiURL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Encoding", "gzip")
+		if useCompression {
+			req.Header.Set("Content-Encoding", config.Compressor.Encoding)
+		}
+
+		// Track request bytes (uncompressed vs on the wire) for every attempt
+		atomic.AddInt64(&metrics.RequestBytes, int64(len(payload)))
+		atomic.AddInt64(&metrics.RequestBytesSent, int64(len(body)))
+
+		// Track active requests
+		atomic.AddInt64(&metrics.ActiveRequests, 1)
+		atomic.AddInt64(&metrics.TotalRequests, 1)
+
+		apiStart := time.Now()
+		resp, err := client.Do(req)
+		apiDuration := time.Since(apiStart)
+		if resp != nil {
+		}
+
+		atomic.AddInt64(&metrics.ActiveRequests, -1)
+		metrics.AddTime("api_call", apiDuration)
+
+		// Track API latency for live metrics
+		latencyNanos := apiDuration.Nanoseconds()
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		metrics.Latency.Observe(apiDuration, status, attemptsSent > 0)
+		attemptsSent++
+		atomic.AddInt64(&metrics.TotalAPILatency, latencyNanos)
+
+		// Update min latency (atomic compare-and-swap loop)
+		for {
+			oldMin := atomic.LoadInt64(&metrics.MinAPILatency)
+			if oldMin != 0 && oldMin <= latencyNanos {
+				break // Current min is smaller, no update needed
+			}
+			if atomic.CompareAndSwapInt64(&metrics.MinAPILatency, oldMin, latencyNanos) {
+				break
+			}
+		}
+
+		// Update max latency (atomic compare-and-swap loop)
+		for {
+			oldMax := atomic.LoadInt64(&metrics.MaxAPILatency)
+			if oldMax >= latencyNanos {
+				break // Current max is larger, no update needed
+			}
+			if atomic.CompareAndSwapInt64(&metrics.MaxAPILatency, oldMax, latencyNanos) {
+				break
+			}
+		}
+
+		if err != nil {
+			if attempt < maxRetries-1 {
+				hadRetry = true
+				retryStart := time.Now()
+				backoff := time.Duration(1<<uint(attempt)) * time.Second
+				time.Sleep(backoff)
+				metrics.AddTime("retry_delay", time.Since(retryStart))
+				tracer.Record(span, "retry.backoff", retryStart, attribute.Int("retry.attempt", attempt+1))
+				continue
+			}
+			metrics.AddFailedBatch()
+			err = fmt.Errorf("API request failed after retries: %w", err)
+			// Log batch error for later review (status 0: no response)
+			metrics.BatchErrorsMutex.Lock()
+			metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
+				BatchNumber: batchNum,
+				Records:     batch,
+				Error:       err.Error(),
+				Timestamp:   time.Now(),
+			})
+			metrics.BatchErrorsMutex.Unlock()
+			return err
+		}
+
+		// Read body for error diagnostics
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode == 200 || resp.StatusCode == 201 {
+			// Record skyflow_ids; the records are already in the vault, so a crosswalk
+			// failure is reported but does not fail the batch
+			if config.Crosswalk != nil {
+				entries, err := buildCrosswalkEntries(vaultConfig, batchNum, batch, bodyBytes, config.Crosswalk.HashValue)
+				if err == nil {
+					err = config.Crosswalk.Write(entries)
+				} else {
+					atomic.AddInt64(&config.Crosswalk.Failed, int64(len(batch)))
+				}
+				if err != nil {
+					blog.Warn(fmt.Sprintf("Batch %d: Failed to record skyflow_id crosswalk: %v", batchNum, err), "error", err)
+				}
+			}
+
+			// Track whether this was immediate success or after retry
+			if hadRetry {
+				atomic.AddInt64(&metrics.RetriedSuccesses, 1)
+			} else {
+				atomic.AddInt64(&metrics.ImmediateSuccesses, 1)
+			}
+			metrics.AddSuccessfulBatch()
+
+			// continueOnError: the batch succeeds even if the vault rejected some of its records.
+			// Only accepted records count as loaded; rejected ones go to the error log for replay.
+			rejected, status, message := rejectedRecords(batch, bodyBytes)
+			metrics.AddRecords(len(batch) - len(rejected))
+			if len(rejected) > 0 {
+				atomic.AddInt64(&metrics.RecordErrors, int64(len(rejected)))
+				metrics.BatchErrorsMutex.Lock()
+				metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
+					BatchNumber: batchNum,
+					Records:     rejected,
+					Error:       fmt.Sprintf("vault rejected %d of %d records: %s", len(rejected), len(batch), message),
+					StatusCode:  status,
+					Timestamp:   time.Now(),
+					Partial:     true,
+				})
+				metrics.BatchErrorsMutex.Unlock()
+				span.SetAttributes(attribute.Int("batch.records_rejected", len(rejected)))
+				blog.Warn(fmt.Sprintf("Batch %d: Vault rejected %d of %d records: %s", batchNum, len(rejected), len(batch), redactPII(message)),
+					"records_rejected", len(rejected), "status", status)
+			}
+			return nil
+		}
+
+		// Expired/revoked token: mint a fresh one and retry once without using a retry attempt
+		if resp.StatusCode == 401 && !authRetried && config.Auth.CanRefresh() {
+			authRetried = true
+			config.Auth.Invalidate(bearerToken)
+			blog.Info(fmt.Sprintf("🔑 Batch %d: Bearer token rejected (401), refreshing token and retrying", batchNum), "status", resp.StatusCode)
+			attempt--
+			continue
+		}
+
+		// Server can't read compressed bodies: switch compression off and resend this batch
+		// uncompressed without using a retry attempt
+		if useCompression && !compressionRetried && rejectsCompression(resp.StatusCode, bodyBytes) {
+			compressionRetried = true
+			if config.Compressor.Disable() {
+				blog.Warn(fmt.Sprintf("Batch %d: Server rejected %s request body (%d), sending uncompressed from now on",
+					batchNum, config.Compressor.Encoding, resp.StatusCode), "status", resp.StatusCode, "encoding", config.Compressor.Encoding)
+			}
+			attempt--
+			continue
+		}
+
+		// Log non-success responses for diagnostics
+		if resp.StatusCode == 429 {
+			atomic.AddInt64(&metrics.RateLimited429, 1)
+			hadRetry = true
+			blog.Warn(fmt.Sprintf("Batch %d: Rate limited (429), retrying in %d seconds (attempt %d/%d)",
+				batchNum, 2<<uint(attempt), attempt+1, maxRetries), "status", resp.StatusCode, "attempt", attempt+1)
+		} else if resp.StatusCode >= 500 {
+			atomic.AddInt64(&metrics.ServerErrors5xx, 1)
+			hadRetry = true
+		}
+
+		// Handle retryable errors
+		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
+			if attempt < maxRetries-1 {
+				retryStart := time.Now()
+				backoff := time.Duration(2<<uint(attempt)) * time.Second
+				time.Sleep(backoff)
+				metrics.AddTime("retry_delay", time.Since(retryStart))
+				tracer.Record(span, "retry.backoff", retryStart, attribute.Int("retry.attempt", attempt+1))
+				continue
+			}
+			// Max retries reached for retryable error
+			metrics.AddFailedBatch()
+			err := fmt.Errorf("API request failed with status %d after %d retries (body: %s)",
+				resp.StatusCode, maxRetries, redactResponseBody(bodyBytes, batch))
+			// Log batch error for later review
+			metrics.BatchErrorsMutex.Lock()
+			metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
+				BatchNumber: batchNum,
+				Records:     batch,
+				Error:       err.Error(),
+				StatusCode:  resp.StatusCode,
+				Timestamp:   time.Now(),
+			})
+			metrics.BatchErrorsMutex.Unlock()
+			return err
+		}
+
+		// Non-retryable error (4xx other than 429)
+		metrics.AddFailedBatch()
+		responseBody := redactResponseBody(bodyBytes, batch)
+		blog.Error(fmt.Sprintf("Batch %d: Non-retryable error %d (body: %s)", batchNum, resp.StatusCode, responseBody),
+			"status", resp.StatusCode)
+		err = fmt.Errorf("API request failed with non-retryable status %d", resp.StatusCode)
+		// Log batch error for later review
+		metrics.BatchErrorsMutex.Lock()
+		metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
+			BatchNumber: batchNum,
+			Records:     batch,
+			Error:       err.Error(),
+			StatusCode:  resp.StatusCode,
+			Timestamp:   time.Now(),
+		})
+		metrics.BatchErrorsMutex.Unlock()
+		return err
+	}
+
+	metrics.AddFailedBatch()
+	networkErr := fmt.Errorf("max retries exceeded due to network errors")
+	// Log batch error for later review
+	metrics.BatchErrorsMutex.Lock()
+	metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
+		BatchNumber: batchNum,
+		Records:     batch,
+		Error:       networkErr.Error(),
+		StatusCode:  0,
+		Timestamp:   time.Now(),
+	})
+	metrics.BatchErrorsMutex.Unlock()
+	return networkErr
+}
+
+// Process a single vault
+func processVault(config *Config, vaultConfig VaultConfig, dataSource DataSource) *Metrics {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
+	fmt.Printf("PROCESSING %s DATA\n", vaultConfig.Name)
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+
+	metrics := &Metrics{
+		VaultName: vaultConfig.Name,
+		StartTime: time.Now(),
+		Endpoint:  config.VaultURL,
+	}
+	config.Exporter.Register(metrics, config.MaxConcurrency)
+	notifier.Track(metrics)
+	defer notifier.Done(metrics)
+	metrics.Trace = tracer.Start(nil, "vault "+vaultConfig.Name,
+		attribute.String("vault.name", vaultConfig.Name), attribute.String("vault.id", vaultConfig.ID),
+		attribute.String("vault.table", vaultConfig.TableName()), attribute.String("skyflow.endpoint", config.VaultURL),
+		attribute.Int("loader.batch_size", config.BatchSize), attribute.Int("loader.concurrency", config.MaxConcurrency))
+	defer func() {
+		metrics.Trace.SetAttributes(
+			attribute.Int64("records.source", metrics.SourceRecords), attribute.Int64("records.planned", metrics.PlannedRecords),
+			attribute.Int64("records.loaded", metrics.TotalRecords), attribute.Int64("batches.successful", metrics.SuccessfulBatches),
+			attribute.Int64("batches.failed", metrics.FailedBatches), attribute.Int64("http.requests", metrics.TotalRequests),
+			attribute.Int64("http.rate_limited", metrics.RateLimited429))
+		if metrics.Error != "" {
+			setSpanError(metrics.Trace, metrics.Error)
+		} else if metrics.FailedBatches > 0 {
+			setSpanError(metrics.Trace, fmt.Sprintf("%d batches failed", metrics.FailedBatches))
+		}
+		metrics.Trace.End()
+	}()
+	vlog := logger.With("component", "loader", "vault", vaultConfig.Name)
+	workerLog := logger.With("component", "worker", "vault", vaultConfig.Name)
+	progressLog := logger.With("component", "progress", "vault", vaultConfig.Name)
+	liveLog := logger.With("component", "live", "vault", vaultConfig.Name)
+	if vaultConfig.HasOverrides() {
+		fmt.Printf("🔀 Vault overrides: endpoint %s | batch size %d | concurrency %d | base delay %dms\n",
+			config.VaultURL, config.BatchSize, config.MaxConcurrency, config.BaseRequestDelay.Milliseconds())
+	}
+
+	// Read data from source
+	readStart := time.Now()
+	records, err := dataSource.ReadRecords(vaultConfig, config.MaxRecords)
+	if err != nil {
+		vlog.Error(fmt.Sprintf("Failed to read data: %v", err), "error", err)
+		metrics.Error = fmt.Sprintf("failed to read data: %v", err)
+		metrics.EndTime = time.Now()
+		return metrics
+	}
+	metrics.AddTime("csv_read", time.Since(readStart))
+	metrics.SourceRecords = int64(len(records))
+	tracer.Record(metrics.Trace, "source.read", readStart, attribute.String("source.type", config.DataSource), attribute.Int("records", len(records)))
+
+	// Normalize values before any payload is built
+	transformStart := time.Now()
+	metrics.Transforms, err = applyVaultTransforms(vaultConfig, dataSource, records)
+	if err != nil {
+		vlog.Error(fmt.Sprintf("Invalid transforms: %v", err), "error", err)
+		metrics.Error = fmt.Sprintf("invalid transforms: %v", err)
+		metrics.EndTime = time.Now()
+		return metrics
+	}
+	if metrics.Transforms != nil {
+		metrics.AddTime("transform", time.Since(transformStart))
+		tracer.Record(metrics.Trace, "transform", transformStart)
+	}
+
+	// Reject records failing the vault's validation rules before batching
+	validateStart := time.Now()
+	var rows []int
+	records, rows, metrics.Validation, err = applyVaultValidation(vaultConfig, dataSource, records, config.Rejects, config.Upsert)
+	if err != nil {
+		vlog.Error(fmt.Sprintf("Validation failed: %v", err), "error", err)
+		metrics.Error = fmt.Sprintf("validation failed: %v", err)
+		metrics.EndTime = time.Now()
+		return metrics
+	}
+	if v := metrics.Validation; v != nil {
+		tracer.Record(metrics.Trace, "validate", validateStart, attribute.Int64("records.checked", v.Checked), attribute.Int64("records.rejected", v.Rejected))
+	}
+	if v := metrics.Validation; v != nil && v.Rejected > 0 {
+		fmt.Printf("  🚫 Rejected %s of %s records failing validation rules\n",
+			formatNumber(int(v.Rejected)), formatNumber(int(v.Checked)))
+	}
+
+	// Drop duplicate pairs and resolve token/value conflicts before batching
+	dedupStart := time.Now()
+	records, metrics.Dedup, err = config.Dedup.Apply(vaultConfig, dataSource, records, rows)
+	if metrics.Dedup != nil {
+		metrics.AddTime("dedup", time.Since(dedupStart))
+		d := metrics.Dedup
+		tracer.Record(metrics.Trace, "dedup", dedupStart,
+			attribute.Int("records.duplicates", d.Duplicates), attribute.Int("records.conflicts_dropped", d.ConflictsDropped))
+		fmt.Printf("  🧹 Dedup: %s duplicates dropped | %s value→token and %s token→value conflicts | %s conflicting records dropped\n",
+			formatNumber(d.Duplicates), formatNumber(d.ValueConflicts), formatNumber(d.TokenConflicts), formatNumber(d.ConflictsDropped))
+	}
+	if err != nil {
+		vlog.Error(err.Error(), "error", err)
+		metrics.Aborted = true
+		metrics.Error = err.Error()
+		metrics.EndTime = time.Now()
+		return metrics
+	}
+
+	// Display appropriate message based on source
+	sourceType := "data source"
+	if config.DataSource == "snowflake" {
+		sourceType = "Snowflake"
+	} else if config.DataSource == "csv" {
+		sourceType = "CSV files"
+	}
+	fmt.Printf("📊 Loaded %d records from %s\n", len(records), sourceType)
+	atomic.StoreInt64(&metrics.PlannedRecords, int64(len(records)))
+
+	// Calculate dynamic progress interval (report every 1%, but keep reasonable bounds)
+	// Minimum: 10,000 records, Maximum: 1,000,000 records
+	progressInterval := len(records) / 100 // 1% of total
+	if progressInterval < 10000 {
+		progressInterval = 10000
+	}
+	if progressInterval > 1000000 {
+		progressInterval = 1000000
+	}
+	config.ProgressInterval = progressInterval // Update config with calculated interval
+	fmt.Printf("📈 Progress updates every %d records (~%.1f%%)\n", progressInterval, float64(progressInterval)/float64(len(records))*100)
+
+	// Batches are cut as they are queued, so a batch size changed through the control API
+	// applies to the rest of the vault
+	batchSize := controller.BatchSize(config.BatchSize)
+	concurrency := controller.Concurrency(config.MaxConcurrency)
+	fmt.Printf("🔥 Processing %d batches with %d concurrent workers\n", (len(records)+batchSize-1)/batchSize, concurrency)
+
+	// Create shared HTTP client (connection pooling scaled to the most workers the vault can reach,
+	// including control API increases; attempts are traced as HTTP client spans when tracing is on)
+	client := createHTTPClient(controller.MaxConcurrency(concurrency))
+	client.Transport = tracer.Transport(client.Transport)
+
+	// Pre-construct API URL (avoid repeated string formatting in hot path)
+	apiURL := fmt.Sprintf("%s/v1/vaults/%s/%s", config.VaultURL, vaultConfig.ID, vaultConfig.TableName())
+
+	// Process batches concurrently with worker pool
+	type batchJob struct {
+		num   int
+		start int // Offset of the batch's first record
+		batch []Record
+	}
+	var wg sync.WaitGroup
+	batchChan := make(chan batchJob, concurrency*2) // Buffered channel for pipelining
+	controller.Attach(config, metrics)
+	defer controller.Detach()
+
+	// Start workers (more are started while feeding if the control API raises concurrency;
+	// workers above a lowered limit wait for a slot)
+	workers := 0
+	startWorker := func() {
+		workers++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range batchChan {
+				// Wait while paused or above the concurrency limit, then track active worker
+				controller.Acquire(config.MaxConcurrency)
+				atomic.AddInt64(&metrics.ActiveWorkers, 1)
+
+				err := sendBatch(client, config, vaultConfig, apiURL, job.batch, job.num, metrics)
+
+				atomic.AddInt64(&metrics.ActiveWorkers, -1)
+				controller.Release()
+
+				if err != nil {
+					// Log error with batch details
+					recordStart := job.start
+					recordEnd := recordStart + len(job.batch)
+					workerLog.Error(fmt.Sprintf("Batch %d FAILED (records %d-%d): %v", job.num, recordStart, recordEnd, err),
+						"batch", job.num, "error", err)
+				}
+
+				// Progress reporting with HTTP status breakdown
+				totalRecords := atomic.LoadInt64(&metrics.TotalRecords)
+				successBatches := atomic.LoadInt64(&metrics.SuccessfulBatches)
+				failedBatches := atomic.LoadInt64(&metrics.FailedBatches)
+				rateLimited := atomic.LoadInt64(&metrics.RateLimited429)
+				immediate := atomic.LoadInt64(&metrics.ImmediateSuccesses)
+				retried := atomic.LoadInt64(&metrics.RetriedSuccesses)
+
+				// Report every N successful records
+				if totalRecords > 0 && totalRecords%int64(config.ProgressInterval) == 0 {
+					elapsed := time.Since(metrics.StartTime).Seconds()
+					rate := float64(totalRecords) / elapsed
+					totalBatches := successBatches + failedBatches
+					successRate := float64(successBatches) / float64(totalBatches) * 100
+
+					progressLog.Info(fmt.Sprintf("Progress: %d/%d records (%.1f%%) - %.0f records/sec | Batches: %d✅ (%d immediate, %d retried) %d❌ (%.0f%% success) | 429s: %d",
+						totalRecords, len(records),
+						float64(totalRecords)/float64(len(records))*100, rate,
+						successBatches, immediate, retried, failedBatches, successRate, rateLimited),
+						"records", totalRecords, "planned", len(records), "records_per_sec", rate,
+						"batches_ok", successBatches, "batches_failed", failedBatches, "rate_limited", rateLimited)
+				}
+			}
+		}()
+	}
+	for workers < concurrency {
+		startWorker()
+	}
+
+	// Start real-time metrics reporter
+	stopMetrics := make(chan struct{})
+	var metricsWg sync.WaitGroup
+	metricsWg.Add(1)
+	go func() {
+		defer metricsWg.Done()
+		ticker := time.NewTicker(3 * time.Second)
+		defer ticker.Stop()
+
+		lastRequests := int64(0)
+		lastTime := time.Now()
+
+		for {
+			select {
+			case <-stopMetrics:
+				return
+			case <-ticker.C:
+				now := time.Now()
+				currentRequests := atomic.LoadInt64(&metrics.TotalRequests)
+				deltaRequests := currentRequests - lastRequests
+				deltaTime := now.Sub(lastTime).Seconds()
+				requestRate := float64(deltaRequests) / deltaTime
+
+				activeWorkers := atomic.LoadInt64(&metrics.ActiveWorkers)
+				activeRequests := atomic.LoadInt64(&metrics.ActiveRequests)
+				rateLimited := atomic.LoadInt64(&metrics.RateLimited429)
+				totalRecords := atomic.LoadInt64(&metrics.TotalRecords)
+
+				elapsed := now.Sub(metrics.StartTime).Seconds()
+				recordRate := float64(totalRecords) / elapsed
+
+				// Calculate average API latency
+				totalLatencyNanos := atomic.LoadInt64(&metrics.TotalAPILatency)
+				avgLatencyMs := 0.0
+				if currentRequests > 0 {
+					avgLatencyMs = float64(totalLatencyNanos) / float64(currentRequests) / 1_000_000
+				}
+
+				// Get min/max latency and tail percentiles
+				minLatencyNanos := atomic.LoadInt64(&metrics.MinAPILatency)
+				maxLatencyNanos := atomic.LoadInt64(&metrics.MaxAPILatency)
+				minLatencyMs := float64(minLatencyNanos) / 1_000_000
+				maxLatencyMs := float64(maxLatencyNanos) / 1_000_000
+				latency := metrics.Latency.All.Snapshot()
+				paused := ""
+				if controller.Paused() {
+					paused = " | PAUSED"
+				}
+
+				liveLog.Info(fmt.Sprintf("[LIVE] Workers: %d/%d | HTTP: %d in-flight | Req: %.0f/s | Rec: %.0f/s | Latency: avg=%.0fms min=%.0fms p50=%sms p90=%sms p99=%sms p99.9=%sms max=%.0fms | 429s: %d%s",
+					activeWorkers, controller.Concurrency(config.MaxConcurrency),
+					activeRequests,
+					requestRate,
+					recordRate,
+					avgLatencyMs,
+					minLatencyMs,
+					formatLatencyMs(latency.Percentile(50)),
+					formatLatencyMs(latency.Percentile(90)),
+					formatLatencyMs(latency.Percentile(99)),
+					formatLatencyMs(latency.Percentile(99.9)),
+					maxLatencyMs,
+					rateLimited, paused),
+					"active_workers", activeWorkers, "paused", paused != "", "in_flight", activeRequests,
+					"requests_per_sec", requestRate, "records_per_sec", recordRate,
+					"p50_ms", latency.Percentile(50).Seconds()*1000, "p99_ms", latency.Percentile(99).Seconds()*1000,
+					"rate_limited", rateLimited)
+
+				lastRequests = currentRequests
+				lastTime = now
+			}
+		}
+	}()
+
+	// Feed batches to workers
+	for batchNum, start := 0, 0; start < len(records); batchNum++ {
+		end := min(start+controller.BatchSize(config.BatchSize), len(records))
+		for workers < controller.Concurrency(config.MaxConcurrency) {
+			startWorker()
+		}
+		batchChan <- batchJob{num: batchNum, start: start, batch: records[start:end]}
+		start = end
+	}
+	close(batchChan)
+
+	wg.Wait()
+
+	// Stop metrics reporter
+	close(stopMetrics)
+	metricsWg.Wait()
+
+	metrics.EndTime = time.Now()
+
+	totalRecords := atomic.LoadInt64(&metrics.TotalRecords)
+	successBatches := atomic.LoadInt64(&metrics.SuccessfulBatches)
+	failedBatches := atomic.LoadInt64(&metrics.FailedBatches)
+	totalBatches := successBatches + failedBatches
+
+	vlog.Info(fmt.Sprintf("✅ %s processing complete: %d records uploaded | %d/%d batches successful (%.1f%%)",
+		vaultConfig.Name, totalRecords,
+		successBatches, totalBatches,
+		float64(successBatches)/float64(totalBatches)*100),
+		"records", totalRecords, "batches_ok", successBatches, "batches_failed", failedBatches)
+
+	// Write error log if there were failures
+	if len(metrics.BatchErrors) > 0 {
+		if err := writeErrorLog(vaultConfig, metrics); err != nil {
+			vlog.Warn(fmt.Sprintf("Failed to write error log: %v", err), "error", err)
+		}
+	}
+
+	return metrics
+}
+
+// writeErrorLog creates a JSON file with details of failed batches
+func writeErrorLog(vaultConfig VaultConfig, metrics *Metrics) error {
+	if len(metrics.BatchErrors) == 0 {
+		return nil
+	}
+
+	// Create filename with timestamp
+	timestamp := time.Now().Format("20060102_150405")
+	filename := fmt.Sprintf("error_log_%s_%s.json", vaultConfig.Name, timestamp)
+
+	// Create error log structure
+	errorLog := struct {
+		VaultName     string       `json:"vault_name"`
+		VaultID       string       `json:"vault_id"`
+		Table         string       `json:"table"`
+		Column        string       `json:"column"`
+		Timestamp     time.Time    `json:"timestamp"`
+		TotalErrors   int          `json:"total_errors"`
+		FailedRecords int          `json:"failed_records"`
+		Errors        []BatchError `json:"errors"`
+	}{
+		VaultName:     vaultConfig.Name,
+		VaultID:       vaultConfig.ID,
+		Table:         vaultConfig.TableName(),
+		Column:        vaultConfig.Column,
+		Timestamp:     time.Now(),
+		TotalErrors:   len(metrics.BatchErrors),
+		FailedRecords: 0,
+		Errors:        metrics.BatchErrors,
+	}
+
+	// Count total failed records
+	for _, batchErr := range metrics.BatchErrors {
+		errorLog.FailedRecords += len(batchErr.Records)
+	}
+
+	// Write to file
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create error log file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(errorLog); err != nil {
+		return fmt.Errorf("failed to write error log: %w", err)
+	}
+
+	metrics.ErrorLogPath = filename
+	fmt.Printf("  📋 Error log written to: %s (%d batches, %d records)\n",
+		filename, errorLog.TotalErrors, errorLog.FailedRecords)
+
+	return nil
+}
+
+// Display performance summary
+func displaySummary(allMetrics []*Metrics, totalStart time.Time, config *Config) {
+	totalElapsed := time.Since(totalStart)
+
+	fmt.Printf("\n%s\n", strings.Repeat("=", 100))
+	fmt.Printf("COMPREHENSIVE PERFORMANCE SUMMARY\n")
+	fmt.Printf("%s\n", strings.Repeat("=", 100))
+
+	totalRecords := int64(0)
+	totalSuccessful := int64(0)
+	totalFailed := int64(0)
+
+	var endpoints []string
+	for _, m := range allMetrics {
+		if !slices.Contains(endpoints, m.Endpoint) {
+			endpoints = append(endpoints, m.Endpoint)
+		}
+	}
+
+	for _, m := range allMetrics {
+		records := atomic.LoadInt64(&m.TotalRecords)
+		successful := atomic.LoadInt64(&m.SuccessfulBatches)
+		failed := atomic.LoadInt64(&m.FailedBatches)
+
+		if records > 0 || successful > 0 || failed > 0 {
+			fmt.Printf("\n%s VAULT PERFORMANCE:\n", m.VaultName)
+			if len(endpoints) > 1 {
+				fmt.Printf("  Endpoint:              %s\n", m.Endpoint)
+			}
+			fmt.Printf("  Records Uploaded:      %d (successfully processed)\n", records)
+			fmt.Printf("  Processing Time:       %.2f seconds\n", m.Duration().Seconds())
+			fmt.Printf("  Throughput:            %.0f records/sec (successful only)\n", m.Throughput())
+			totalBatches := successful + failed
+			if totalBatches > 0 {
+				fmt.Printf("  Batch Success Rate:    %d/%d batches (%.1f%%)\n",
+					successful, totalBatches,
+					float64(successful)/float64(totalBatches)*100)
+			}
+			if recordErrors := atomic.LoadInt64(&m.RecordErrors); recordErrors > 0 {
+				fmt.Printf("  Records Rejected:      %d (by the vault, in successful batches - see error log)\n", recordErrors)
+			}
+
+			// API response summary
+			rateLimited := atomic.LoadInt64(&m.RateLimited429)
+			serverErrors := atomic.LoadInt64(&m.ServerErrors5xx)
+			immediate := atomic.LoadInt64(&m.ImmediateSuccesses)
+			retried := atomic.LoadInt64(&m.RetriedSuccesses)
+
+			if rateLimited > 0 || serverErrors > 0 || retried > 0 {
+				fmt.Printf("\n  API RESPONSE SUMMARY:\n")
+				if successful > 0 {
+					immediateRate := float64(immediate) / float64(successful) * 100
+					retriedRate := float64(retried) / float64(successful) * 100
+					fmt.Printf("    ✅ Immediate Successes: %d (%.1f%% of batches)\n", immediate, immediateRate)
+					fmt.Printf("    🔄 Retried Successes:   %d (%.1f%% of batches)\n", retried, retriedRate)
+				}
+				if rateLimited > 0 {
+					fmt.Printf("    ⚠️  Rate Limited (429):  %d responses during execution\n", rateLimited)
+				}
+				if serverErrors > 0 {
+					fmt.Printf("    ⚠️  Server Errors (5xx): %d responses during execution\n", serverErrors)
+				}
+			}
+
+			// API latency percentiles: all requests, first attempts vs retries, per status class
+			if all := m.Latency.All.Snapshot(); all.Count > 0 {
+				fmt.Printf("\n  API LATENCY (ms):         %10s %8s %8s %8s %8s %8s\n", "Requests", "p50", "p90", "p99", "p99.9", "max")
+				printLatency := func(label string, snapshot *LatencySnapshot) {
+					if snapshot.Count == 0 {
+						return
+					}
+					fmt.Printf("    %-22s %10s %8s %8s %8s %8s %8s\n", label, formatNumber(int(snapshot.Count)),
+						formatLatencyMs(snapshot.Percentile(50)), formatLatencyMs(snapshot.Percentile(90)),
+						formatLatencyMs(snapshot.Percentile(99)), formatLatencyMs(snapshot.Percentile(99.9)),
+						formatLatencyMs(snapshot.Max))
+				}
+				printLatency("All requests", all)
+				printLatency("First attempts", m.Latency.FirstAttempt.Snapshot())
+				printLatency("Retries", m.Latency.Retried.Snapshot())
+				for class, name := range latencyStatusClasses {
+					label := "Status " + name
+					if name == "network_error" {
+						label = "Network errors"
+					}
+					printLatency(label, m.Latency.ByStatus[class].Snapshot())
+				}
+			}
+
+			// Value transforms
+			if t := m.Transforms; t != nil {
+				fmt.Printf("\n  VALUE TRANSFORMS:\n")
+				fmt.Printf("    Transformed:           %s values\n", formatNumber(int(atomic.LoadInt64(&t.Transformed))))
+				fmt.Printf("    Untouched:             %s values\n", formatNumber(int(atomic.LoadInt64(&t.Untouched))))
+				if failed := atomic.LoadInt64(&t.Failed); failed > 0 {
+					fmt.Printf("    ⚠️  Not transformable:   %s values (sent unchanged by the failing step)\n", formatNumber(int(failed)))
+				}
+				printStep := func(prefix string, step *TransformStep) {
+					fmt.Printf("      %-40s changed %s", prefix+step.Name, formatNumber(int(atomic.LoadInt64(&step.Changed))))
+					if failed := atomic.LoadInt64(&step.Failed); failed > 0 {
+						fmt.Printf(", failed %s", formatNumber(int(failed)))
+					}
+					fmt.Printf("\n")
+				}
+				for _, step := range t.Steps {
+					printStep("", step)
+				}
+				columns := make([]string, 0, len(t.Columns))
+				for column := range t.Columns {
+					columns = append(columns, column)
+				}
+				sort.Strings(columns)
+				for _, column := range columns {
+					for _, step := range t.Columns[column] {
+						printStep(column+": ", step)
+					}
+				}
+			}
+
+			// Validation rules
+			if v := m.Validation; v != nil {
+				fmt.Printf("\n  VALIDATION:\n")
+				fmt.Printf("    Checked:               %s records\n", formatNumber(int(v.Checked)))
+				fmt.Printf("    Passed:                %s records\n", formatNumber(int(v.Checked-v.Rejected)))
+				fmt.Printf("    Rejected:              %s records\n", formatNumber(int(v.Rejected)))
+				printCheck := func(prefix string, check *ValidationCheck) {
+					fmt.Printf("      %-40s rejected %s\n", prefix+check.Name, formatNumber(int(check.Rejected)))
+				}
+				if v.UpsertKey != nil {
+					printCheck("", v.UpsertKey)
+				}
+				for _, check := range v.Checks {
+					printCheck("", check)
+				}
+				columns := make([]string, 0, len(v.Columns))
+				for column := range v.Columns {
+					columns = append(columns, column)
+				}
+				sort.Strings(columns)
+				for _, column := range columns {
+					for _, check := range v.Columns[column] {
+						printCheck(column+": ", check)
+					}
+				}
+			}
+
+			// Deduplication
+			if d := m.Dedup; d != nil {
+				fmt.Printf("\n  DEDUPLICATION:\n")
+				fmt.Printf("    Duplicates Dropped:    %s records\n", formatNumber(d.Duplicates))
+				fmt.Printf("    Value→Token Conflicts: %s values\n", formatNumber(d.ValueConflicts))
+				fmt.Printf("    Token→Value Conflicts: %s tokens\n", formatNumber(d.TokenConflicts))
+				fmt.Printf("    Conflicts Dropped:     %s records (policy %s)\n", formatNumber(d.ConflictsDropped), config.Dedup.Policy)
+			}
+
+			// Detailed timing
+			csvRead := m.GetDuration("csv_read")
+			recordCreation := m.GetDuration("record_creation")
+			suffixGen := m.GetDuration("suffix_gen")
+			payloadCreation := m.GetDuration("payload_creation")
+			jsonSer := m.GetDuration("json_serialization")
+			compression := m.GetDuration("compression")
+			transform := m.GetDuration("transform")
+			dedup := m.GetDuration("dedup")
+			baseDelay := m.GetDuration("base_delay")
+			apiCall := m.GetDuration("api_call")
+			retryDelay := m.GetDuration("retry_delay")
+
+			cumulative := csvRead + transform + dedup + recordCreation + suffixGen + payloadCreation +
+				jsonSer + compression + baseDelay + apiCall + retryDelay
+
+			avgConcurrency := cumulative.Seconds() / m.Duration().Seconds()
+
+			fmt.Printf("\n  DETAILED TIMING BREAKDOWN (Cumulative across all parallel workers):\n")
+			fmt.Printf("    %-25s %12s %10s %16s\n", "Component", "Cumulative", "% of Total", "Est. Wall Clock")
+			fmt.Printf("    %s %s %s %s\n", strings.Repeat("-", 25), strings.Repeat("-", 12), strings.Repeat("-", 10), strings.Repeat("-", 16))
+
+			printTiming := func(label string, dur time.Duration, warn bool) {
+				pct := 0.0
+				if cumulative.Seconds() > 0 {
+					pct = dur.Seconds() / cumulative.Seconds() * 100
+				}
+				estWall := 0.0
+				if avgConcurrency > 0 {
+					estWall = dur.Seconds() / avgConcurrency
+				}
+				fmt.Printf("    %-25s %10.2fs %9.1f%% %14.2fs\n", label, dur.Seconds(), pct, estWall)
+			}
+
+			printTiming("Data Source Read", csvRead, false)
+			if m.Transforms != nil {
+				printTiming("Value Transforms", transform, false)
+			}
+			if m.Dedup != nil {
+				printTiming("Deduplication", dedup, false)
+			}
+			printTiming("Record Creation", recordCreation, false)
+			printTiming("Suffix Generation", suffixGen, false)
+			printTiming("Payload Creation", payloadCreation, false)
+			printTiming("JSON Serialization", jsonSer, false)
+			if config.Compressor != nil {
+				printTiming("Request Compression", compression, false)
+			}
+			printTiming("BASE_REQUEST_DELAY", baseDelay, false)
+			printTiming("Skyflow API Calls", apiCall, false)
+			printTiming("Retry Delays", retryDelay, false)
+
+			fmt.Printf("    %s %s %s %s\n", strings.Repeat("-", 25), strings.Repeat("-", 12), strings.Repeat("-", 10), strings.Repeat("-", 16))
+			fmt.Printf("    %-25s %10.2fs %9s %14.2fs (actual)\n", "TOTAL (Cumulative)", cumulative.Seconds(), "100.0%", m.Duration().Seconds())
+			fmt.Printf("\n    Average Concurrency: %.1fx (concurrent workers executing simultaneously)\n", avgConcurrency)
+
+			// Request body bytes (all attempts, including retries)
+			if requestBytes := atomic.LoadInt64(&m.RequestBytes); requestBytes > 0 {
+				sentBytes := atomic.LoadInt64(&m.RequestBytesSent)
+				fmt.Printf("\n  REQUEST BYTES:\n")
+				fmt.Printf("    Uncompressed:          %s\n", formatNumber(int(requestBytes)))
+				fmt.Printf("    Sent:                  %s", formatNumber(int(sentBytes)))
+				if sentBytes < requestBytes {
+					fmt.Printf(" (%.1f%% saved, %.1fx ratio)", (1-float64(sentBytes)/float64(requestBytes))*100,
+						float64(requestBytes)/float64(sentBytes))
+				}
+				fmt.Printf("\n")
+			}
+
+			totalRecords += records
+			totalSuccessful += successful
+			totalFailed += failed
+		}
+	}
 
-	// Choose query based on mode
-	var query string
-	switch s.Config.QueryMode {
-	case "union":
-		// D01_SKYFLOW_POC mode: UNION queries with UDF detokenization (hardcoded db/table names)
-		query = s.buildUnionQuery(vaultConfig)
-	case "generic":
-		// Generic mode: UNION queries with configurable db/table names (reads *_TOKEN columns directly)
-		query = s.buildGenericQuery(vaultConfig)
+	// Overall summary
+	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
+	fmt.Printf("OVERALL PERFORMANCE SUMMARY\n")
+	fmt.Printf("%s\n", strings.Repeat("=", 50))
+	fmt.Printf("Total Records Processed:   %d\n", totalRecords)
+	fmt.Printf("Total Elapsed Time (Wall Clock): %.2f seconds\n", totalElapsed.Seconds())
+
+	// Error summary
+	totalBatches := totalSuccessful + totalFailed
+	if totalFailed == 0 {
+		fmt.Printf("\nERROR SUMMARY:\n")
+		fmt.Printf("  ✅ No errors encountered\n")
+		fmt.Printf("  Total Batches:           %d (all successful)\n", totalBatches)
+	} else {
+		successRate := float64(totalSuccessful) / float64(totalBatches) * 100
+		fmt.Printf("\nERROR SUMMARY:\n")
+		fmt.Printf("  Errors detected:\n")
+		fmt.Printf("  Total Batches:           %d\n", totalBatches)
+		fmt.Printf("  Successful Batches:      %d (%.1f%%)\n", totalSuccessful, successRate)
+		fmt.Printf("  Failed Batches:          %d (%.1f%%)\n", totalFailed, 100-successRate)
+	}
+
+	fmt.Printf("\nTHROUGHPUT RATES:\n")
+	fmt.Printf("  End-to-End Throughput:      %.0f records/sec (actual)\n",
+		float64(totalRecords)/totalElapsed.Seconds())
+
+	// Error log summary
+	hasErrors := false
+	for _, m := range allMetrics {
+		if len(m.BatchErrors) > 0 {
+			hasErrors = true
+			break
+		}
+	}
+
+	if hasErrors {
+		fmt.Printf("\n📋 ERROR LOGS CREATED:\n")
+		for _, m := range allMetrics {
+			if len(m.BatchErrors) > 0 {
+				totalFailedRecords := 0
+				for _, batchErr := range m.BatchErrors {
+					totalFailedRecords += len(batchErr.Records)
+				}
+				fmt.Printf("  • %s: error_log_%s_*.json (%d batches, %d records)\n",
+					m.VaultName, m.VaultName, len(m.BatchErrors), totalFailedRecords)
+			}
+		}
+		fmt.Printf("\n  ⚠️  Review error logs and re-run failed records if needed\n")
+	}
+
+	// skyflow_id crosswalk summary
+	if config.Crosswalk != nil {
+		fmt.Printf("\n🔗 SKYFLOW_ID CROSSWALK:\n")
+		fmt.Printf("  Target:                  %s (%s)\n", config.Crosswalk.Target, config.Crosswalk.Format)
+		fmt.Printf("  Entries Written:         %s\n", formatNumber(int(atomic.LoadInt64(&config.Crosswalk.Entries))))
+		if failed := atomic.LoadInt64(&config.Crosswalk.Failed); failed > 0 {
+			fmt.Printf("  ⚠️  Entries Not Written:  %s (records are in the vault; see batch warnings above)\n", formatNumber(int(failed)))
+		}
+	}
+
+	// Validation rejects summary
+	totalRejected := int64(0)
+	for _, m := range allMetrics {
+		if m.Validation != nil {
+			totalRejected += m.Validation.Rejected
+		}
+	}
+	if totalRejected > 0 {
+		fmt.Printf("\n🚫 VALIDATION REJECTS:\n")
+		for _, m := range allMetrics {
+			if m.Validation != nil && m.Validation.Rejected > 0 {
+				fmt.Printf("  • %s: %s of %s records\n", m.VaultName,
+					formatNumber(int(m.Validation.Rejected)), formatNumber(int(m.Validation.Checked)))
+			}
+		}
+		if config.Rejects != nil {
+			fmt.Printf("  Reject File:             %s\n", config.Rejects.Path)
+		}
+	}
+
+	// Per-endpoint rollup when vaults were loaded through more than one Skyflow account
+	if len(endpoints) > 1 {
+		fmt.Printf("\n🔀 ENDPOINTS:\n")
+		for _, endpoint := range endpoints {
+			var records, batches, failed, rateLimited, serverErrors int64
+			var vaultNames []string
+			for _, m := range allMetrics {
+				if m.Endpoint != endpoint {
+					continue
+				}
+				vaultNames = append(vaultNames, m.VaultName)
+				records += atomic.LoadInt64(&m.TotalRecords)
+				batches += atomic.LoadInt64(&m.SuccessfulBatches) + atomic.LoadInt64(&m.FailedBatches)
+				failed += atomic.LoadInt64(&m.FailedBatches)
+				rateLimited += atomic.LoadInt64(&m.RateLimited429)
+				serverErrors += atomic.LoadInt64(&m.ServerErrors5xx)
+			}
+			fmt.Printf("  %s (%s)\n", endpoint, strings.Join(vaultNames, ", "))
+			fmt.Printf("    Records: %s | Batches: %s (%s failed) | 429s: %s | 5xx: %s\n",
+				formatNumber(int(records)), formatNumber(int(batches)), formatNumber(int(failed)),
+				formatNumber(int(rateLimited)), formatNumber(int(serverErrors)))
+		}
+	}
+
+	// Suffix strategy and run tag, so test data can be found and removed later
+	if config.AppendSuffix && config.Suffix != nil {
+		fmt.Printf("\n🏷️  SUFFIX STRATEGY: %s\n", config.Suffix.Strategy)
+		switch config.Suffix.Strategy {
+		case "deterministic":
+			fmt.Printf("  Run Tag:                 %s\n", config.Suffix.RunTag)
+			fmt.Printf("  Values/tokens end in:    _%s_<16 hex chars>\n", config.Suffix.RunTag)
+			fmt.Printf("  Reproduce with:          -append-suffix -suffix-strategy deterministic -run-tag %s\n", config.Suffix.RunTag)
+		case "run-tag":
+			fmt.Printf("  Run Tag:                 %s\n", config.Suffix.RunTag)
+			fmt.Printf("  Values/tokens end in:    _%s\n", config.Suffix.RunTag)
+		default:
+			fmt.Printf("  Run Tag:                 none (suffixes are random and cannot be reproduced)\n")
+		}
+	}
+
+	// Deduplication summary
+	if config.Dedup != nil {
+		duplicates, conflicts, dropped := 0, 0, 0
+		for _, m := range allMetrics {
+			if m.Dedup != nil {
+				duplicates += m.Dedup.Duplicates
+				conflicts += m.Dedup.ValueConflicts + m.Dedup.TokenConflicts
+				dropped += m.Dedup.ConflictsDropped
+			}
+		}
+		fmt.Printf("\n🧹 DEDUPLICATION (%s, policy %s):\n", config.Dedup.Mode, config.Dedup.Policy)
+		fmt.Printf("  Duplicates Dropped:      %s\n", formatNumber(duplicates))
+		fmt.Printf("  Conflicts Found:         %s\n", formatNumber(conflicts))
+		fmt.Printf("  Conflict Rows Dropped:   %s\n", formatNumber(dropped))
+		if duplicates > 0 || conflicts > 0 {
+			fmt.Printf("  Conflict Report:         %s\n", config.Dedup.ReportPath)
+		}
+	}
+
+	// Dry-run summary: what was written and what a real run would take
+	if config.DryRun != nil {
+		fmt.Printf("\n🧪 DRY RUN (no requests sent):\n")
+		fmt.Printf("  Payload File:            %s\n", config.DryRun.Path)
+		fmt.Printf("  Payloads Written:        %d\n", config.DryRun.Payloads)
+		fmt.Printf("  Payload Bytes:           %s (uncompressed)\n", formatNumber(int(config.DryRun.Bytes)))
+		if config.DryRun.Redact {
+			fmt.Printf("  Values/Tokens:           redacted\n")
+		}
+		fmt.Printf("  Estimated Real Runtime:  %s (%d batches, %d workers, %dms base delay)\n",
+			formatEstimate(estimateRuntime(int(totalBatches), config)), totalBatches,
+			config.MaxConcurrency, config.BaseRequestDelay.Milliseconds())
+	}
+
+	// Outcome, matching the exit code
+	switch code, reason := loadOutcome(allMetrics); code {
+	case exitSuccess:
+		fmt.Printf("\n🎉 All vaults processed!\n")
+	case exitPartialFailure:
+		fmt.Printf("\n⚠️  Load finished with failures (exit code %d): %s\n", code, reason)
 	default:
-		// Simple mode (default): ELEVANCE.PUBLIC.PATIENTS table
-		query = s.buildSimpleQuery(vaultConfig)
+		fmt.Printf("\n❌ Load stopped (exit code %d): %s\n", code, reason)
 	}
+}
 
-	// Add LIMIT and OFFSET for manual chunking
-	// Priority: start-record/end-record flags > max-records flag
-	// NOTE: Snowflake syntax requires LIMIT before OFFSET
-	if s.Config.StartRecord > 0 || s.Config.EndRecord > 0 {
-		// Manual chunking mode
-		if s.Config.EndRecord > 0 {
-			limit := s.Config.EndRecord - s.Config.StartRecord
-			query += fmt.Sprintf(" LIMIT %d", limit)
-		} else if maxRecords > 0 {
-			query += fmt.Sprintf(" LIMIT %d", maxRecords)
+// Process exit codes (flag parsing errors exit with 2)
+const (
+	exitSuccess        = 0 // Every selected vault loaded without failed batches
+	exitFatal          = 1 // The run could not start, a mode check failed or a vault was aborted
+	exitPartialFailure = 3 // The run finished but some batches or vaults failed
+)
+
+// reportRedacted replaces secrets in the run report's config snapshot
+const reportRedacted = "[REDACTED]"
+
+// runReport records every run (for -report-file and webhook notifications); every method is nil-safe
+var runReport *RunReport
+
+// RunReport is the machine-readable counterpart of displaySummary, written to -report-file
+type RunReport struct {
+	Path            string          `json:"-"`
+	HistoryPath     string          `json:"-"`    // Run history file (-history-file)
+	Mode            string          `json:"mode"` // load, dry-run, error-log, verify or reconcile
+	StartedAt       time.Time       `json:"started_at"`
+	FinishedAt      time.Time       `json:"finished_at"`
+	DurationSeconds float64         `json:"duration_seconds"`
+	Status          string          `json:"status"` // success, partial_failure or fatal_error
+	ExitCode        int             `json:"exit_code"`
+	FatalError      string          `json:"fatal_error,omitempty"`
+	Host            ReportHost      `json:"host"`
+	Config          *ReportConfig   `json:"config,omitempty"`
+	Source          *ReportSource   `json:"source,omitempty"`
+	Vaults          []*ReportVault  `json:"vaults"`
+	Totals          ReportTotals    `json:"totals"`
+	Errors          ReportErrors    `json:"errors"`
+	Artifacts       ReportArtifacts `json:"artifacts"`
+}
+
+// ReportHost identifies the machine and process that ran the load
+type ReportHost struct {
+	Hostname   string `json:"hostname"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	CPUs       int    `json:"cpus"`
+	GoVersion  string `json:"go_version"`
+	PID        int    `json:"pid"`
+	WorkingDir string `json:"working_dir"`
+}
+
+// ReportConfig is the effective configuration (config file plus CLI overrides) with secrets redacted
+type ReportConfig struct {
+	ConfigFile      string        `json:"config_file"`
+	VaultURL        string        `json:"vault_url"`
+	ManagementURL   string        `json:"management_url"`
+	Auth            string        `json:"auth"` // bearer_token, service_account or none
+	CredentialsFile string        `json:"credentials_file,omitempty"`
+	BatchSize       int           `json:"batch_size"`
+	MaxConcurrency  int           `json:"max_concurrency"`
+	MaxRecords      int           `json:"max_records"`
+	BaseDelayMs     int64         `json:"base_delay_ms"`
+	Upsert          bool          `json:"upsert"`
+	AppendSuffix    bool          `json:"append_suffix"`
+	SuffixStrategy  string        `json:"suffix_strategy,omitempty"`
+	RunTag          string        `json:"run_tag,omitempty"`
+	Compression     string        `json:"request_compression,omitempty"`
+	Dedup           string        `json:"dedup,omitempty"`
+	DedupPolicy     string        `json:"dedup_policy,omitempty"`
+	Network         NetworkConfig `json:"network"`
+	Vaults          []VaultConfig `json:"vaults"`
+}
+
+// ReportSource describes where the records came from (never includes the Snowflake password)
+type ReportSource struct {
+	Type          string           `json:"type"` // csv, snowflake or error-log
+	DataDirectory string           `json:"data_directory,omitempty"`
+	ErrorLog      string           `json:"error_log,omitempty"`
+	Snowflake     *ReportSnowflake `json:"snowflake,omitempty"`
+}
+
+// ReportSnowflake is the Snowflake connection and query used as the source
+type ReportSnowflake struct {
+	Account       string `json:"account"`
+	User          string `json:"user"`
+	Authenticator string `json:"authenticator,omitempty"`
+	Warehouse     string `json:"warehouse"`
+	Database      string `json:"database"`
+	Schema        string `json:"schema"`
+	Role          string `json:"role"`
+	QueryMode     string `json:"query_mode"`
+	Table         string `json:"table,omitempty"`
+	StartRecord   int    `json:"start_record,omitempty"`
+	EndRecord     int    `json:"end_record,omitempty"`
+}
+
+// ReportVault holds one vault's metrics
+type ReportVault struct {
+	Name              string                   `json:"name"`
+	ID                string                   `json:"id"`
+	Table             string                   `json:"table"`
+	Endpoint          string                   `json:"endpoint"`
+	Status            string                   `json:"status"` // success, partial_failure, failed or aborted
+	Error             string                   `json:"error,omitempty"`
+	StartedAt         time.Time                `json:"started_at"`
+	FinishedAt        time.Time                `json:"finished_at"`
+	DurationSeconds   float64                  `json:"duration_seconds"`
+	SourceRecords     int64                    `json:"source_records"`
+	Rejected          int64                    `json:"rejected_records"`
+	DuplicatesDropped int                      `json:"duplicates_dropped"`
+	ConflictsDropped  int                      `json:"conflicts_dropped"`
+	PlannedRecords    int64                    `json:"planned_records"`
+	RecordsLoaded     int64                    `json:"records_loaded"`
+	FailedRecords     int                      `json:"failed_records"` // Records of failed batches plus RecordErrors
+	RecordErrors      int64                    `json:"record_errors"`  // Records rejected individually in successful batches
+	Throughput        float64                  `json:"throughput_records_per_sec"`
+	Batches           ReportBatches            `json:"batches"`
+	Requests          int64                    `json:"http_requests"`
+	RateLimited429    int64                    `json:"rate_limited_429"`
+	ServerErrors5xx   int64                    `json:"server_errors_5xx"`
+	RequestBytes      int64                    `json:"request_bytes"`
+	RequestBytesSent  int64                    `json:"request_bytes_sent"`
+	Latency           map[string]ReportLatency `json:"latency_ms"`     // all, first_attempt, retry and status_<class>
+	Timing            map[string]float64       `json:"timing_seconds"` // AddTime components, summed over workers
+	ErrorLog          string                   `json:"error_log,omitempty"`
+}
+
+// ReportBatches counts finished batches by outcome
+type ReportBatches struct {
+	Total        int64 `json:"total"`
+	FirstAttempt int64 `json:"success_first_attempt"`
+	AfterRetry   int64 `json:"success_after_retry"`
+	Failed       int64 `json:"failed"`
+}
+
+// ReportLatency summarizes one latency histogram in milliseconds
+type ReportLatency struct {
+	Requests int64   `json:"requests"`
+	P50      float64 `json:"p50"`
+	P90      float64 `json:"p90"`
+	P99      float64 `json:"p99"`
+	P999     float64 `json:"p99_9"`
+	Max      float64 `json:"max"`
+}
+
+// ReportTotals sums the vaults
+type ReportTotals struct {
+	RecordsPlanned int64   `json:"records_planned"`
+	RecordsLoaded  int64   `json:"records_loaded"`
+	Throughput     float64 `json:"throughput_records_per_sec"` // Wall clock, all vaults
+}
+
+// ReportErrors is the error summary of the run
+type ReportErrors struct {
+	TotalBatches      int64          `json:"total_batches"`
+	FailedBatches     int64          `json:"failed_batches"`
+	FailedRecords     int            `json:"failed_records"`
+	FailedByStatus    map[string]int `json:"failed_batches_by_status"` // HTTP status ("0" = network error)
+	RecordErrors      int64          `json:"record_errors"`            // Records rejected individually in successful batches
+	RateLimited429    int64          `json:"rate_limited_429"`
+	ServerErrors5xx   int64          `json:"server_errors_5xx"`
+	RejectedRecords   int64          `json:"rejected_records"`
+	CrosswalkFailures int64          `json:"crosswalk_failures"`
+	FailedVaults      []string       `json:"failed_vaults"`
+}
+
+// ReportArtifacts lists the files the run wrote
+type ReportArtifacts struct {
+	ErrorLogs       []string `json:"error_logs"`
+	RejectFile      string   `json:"reject_file,omitempty"`
+	ConflictReport  string   `json:"conflict_report,omitempty"`
+	Crosswalk       string   `json:"crosswalk,omitempty"`
+	DryRunOutput    string   `json:"dry_run_output,omitempty"`
+	VerifyReport    string   `json:"verify_report,omitempty"`
+	ReconcileReport string   `json:"reconcile_report,omitempty"`
+	LogFile         string   `json:"log_file,omitempty"`
+	TraceFile       string   `json:"trace_file,omitempty"`
+}
+
+// NewRunReport starts a run report that Finish writes to path
+func NewRunReport(path string) *RunReport {
+	host := ReportHost{OS: runtime.GOOS, Arch: runtime.GOARCH, CPUs: runtime.NumCPU(), GoVersion: runtime.Version(), PID: os.Getpid()}
+	host.Hostname, _ = os.Hostname()
+	host.WorkingDir, _ = os.Getwd()
+	return &RunReport{Path: path, Mode: "load", StartedAt: time.Now(), Host: host}
+}
+
+// SetConfig snapshots the effective configuration and source, redacting tokens and proxy credentials
+func (r *RunReport) SetConfig(configFile, credentialsFile, errorLogPath string, config *Config, network NetworkConfig, vaults []VaultConfig) {
+	if r == nil {
+		return
+	}
+	rc := &ReportConfig{
+		ConfigFile:     configFile,
+		VaultURL:       config.VaultURL,
+		ManagementURL:  config.ManagementURL,
+		Auth:           config.Auth.Kind(),
+		BatchSize:      config.BatchSize,
+		MaxConcurrency: config.MaxConcurrency,
+		MaxRecords:     config.MaxRecords,
+		BaseDelayMs:    config.BaseRequestDelay.Milliseconds(),
+		Upsert:         config.Upsert,
+		AppendSuffix:   config.AppendSuffix,
+		Network:        network,
+	}
+	if rc.Auth == "service_account" {
+		rc.CredentialsFile = credentialsFile
+	}
+	if config.Suffix != nil {
+		rc.SuffixStrategy = config.Suffix.Strategy
+		rc.RunTag = config.Suffix.RunTag
+	}
+	if config.Compressor != nil {
+		rc.Compression = config.Compressor.Encoding
+	}
+	if config.Dedup != nil {
+		rc.Dedup = config.Dedup.Mode
+		rc.DedupPolicy = config.Dedup.Policy
+	}
+	if proxyURL, err := url.Parse(network.ProxyURL); err == nil && proxyURL.User != nil {
+		rc.Network.ProxyURL = proxyURL.Redacted()
+	}
+	for _, v := range vaults {
+		if v.BearerToken != "" {
+			v.BearerToken = reportRedacted
+		}
+		rc.Vaults = append(rc.Vaults, v)
+	}
+	r.Config = rc
+
+	source := &ReportSource{Type: config.DataSource}
+	switch {
+	case errorLogPath != "":
+		source.Type, source.ErrorLog = "error-log", errorLogPath
+	case config.DataSource == "snowflake":
+		sf := config.SnowflakeConfig
+		source.Snowflake = &ReportSnowflake{
+			Account: sf.Account, User: sf.User, Authenticator: sf.Authenticator, Warehouse: sf.Warehouse,
+			Database: sf.Database, Schema: sf.Schema, Role: sf.Role, QueryMode: sf.QueryMode,
+			Table: sf.SimpleTable, StartRecord: sf.StartRecord, EndRecord: sf.EndRecord,
+		}
+	case config.DataSource == "csv":
+		source.DataDirectory = config.DataDirectory
+	}
+	r.Source = source
+}
+
+// SetSinks records the output files configured for the run
+func (r *RunReport) SetSinks(config *Config) {
+	if r == nil {
+		return
+	}
+	if config.DryRun != nil {
+		r.Artifacts.DryRunOutput = config.DryRun.Path
+	}
+	if config.Crosswalk != nil {
+		r.Artifacts.Crosswalk = config.Crosswalk.Target
+	}
+}
+
+// AddVaults fills in per-vault metrics, totals, the error summary and written artifacts
+func (r *RunReport) AddVaults(allMetrics []*Metrics, vaults []VaultConfig, config *Config, totalElapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	r.Errors.FailedByStatus = make(map[string]int)
+	conflictsFound := false
+	for _, m := range allMetrics {
+		rv := &ReportVault{
+			Name:             m.VaultName,
+			Endpoint:         m.Endpoint,
+			Status:           vaultStatus(m),
+			Error:            m.Error,
+			StartedAt:        m.StartTime,
+			FinishedAt:       m.EndTime,
+			DurationSeconds:  m.Duration().Seconds(),
+			SourceRecords:    m.SourceRecords,
+			PlannedRecords:   atomic.LoadInt64(&m.PlannedRecords),
+			RecordsLoaded:    atomic.LoadInt64(&m.TotalRecords),
+			RecordErrors:     atomic.LoadInt64(&m.RecordErrors),
+			Throughput:       m.Throughput(),
+			Requests:         atomic.LoadInt64(&m.TotalRequests),
+			RateLimited429:   atomic.LoadInt64(&m.RateLimited429),
+			ServerErrors5xx:  atomic.LoadInt64(&m.ServerErrors5xx),
+			RequestBytes:     atomic.LoadInt64(&m.RequestBytes),
+			RequestBytesSent: atomic.LoadInt64(&m.RequestBytesSent),
+			Batches: ReportBatches{
+				FirstAttempt: atomic.LoadInt64(&m.ImmediateSuccesses),
+				AfterRetry:   atomic.LoadInt64(&m.RetriedSuccesses),
+				Failed:       atomic.LoadInt64(&m.FailedBatches),
+			},
+			Latency:  make(map[string]ReportLatency),
+			Timing:   make(map[string]float64),
+			ErrorLog: m.ErrorLogPath,
+		}
+		rv.Batches.Total = rv.Batches.FirstAttempt + rv.Batches.AfterRetry + rv.Batches.Failed
+		for _, v := range vaults {
+			if v.Name == m.VaultName {
+				rv.ID, rv.Table = v.ID, v.TableName()
+			}
+		}
+		if m.Validation != nil {
+			rv.Rejected = m.Validation.Rejected
+		}
+		if d := m.Dedup; d != nil {
+			rv.DuplicatesDropped, rv.ConflictsDropped = d.Duplicates, d.ConflictsDropped
+			conflictsFound = conflictsFound || d.Duplicates > 0 || d.ValueConflicts > 0 || d.TokenConflicts > 0
+		}
+		for _, batchErr := range m.BatchErrors {
+			rv.FailedRecords += len(batchErr.Records)
+			if !batchErr.Partial {
+				r.Errors.FailedByStatus[strconv.Itoa(batchErr.StatusCode)]++
+			}
+		}
+
+		addLatency := func(name string, h *LatencyHistogram) {
+			snapshot := h.Snapshot()
+			if snapshot.Count == 0 {
+				return
+			}
+			ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+			rv.Latency[name] = ReportLatency{
+				Requests: snapshot.Count,
+				P50:      ms(snapshot.Percentile(50)),
+				P90:      ms(snapshot.Percentile(90)),
+				P99:      ms(snapshot.Percentile(99)),
+				P999:     ms(snapshot.Percentile(99.9)),
+				Max:      ms(snapshot.Max),
+			}
+		}
+		addLatency("all", &m.Latency.All)
+		addLatency("first_attempt", &m.Latency.FirstAttempt)
+		addLatency("retry", &m.Latency.Retried)
+		for class, name := range latencyStatusClasses {
+			addLatency("status_"+name, &m.Latency.ByStatus[class])
+		}
+		for _, component := range timingComponents {
+			rv.Timing[component] = m.GetDuration(component).Seconds()
+		}
+
+		r.Vaults = append(r.Vaults, rv)
+		r.Totals.RecordsPlanned += rv.PlannedRecords
+		r.Totals.RecordsLoaded += rv.RecordsLoaded
+		r.Errors.TotalBatches += rv.Batches.Total
+		r.Errors.FailedBatches += rv.Batches.Failed
+		r.Errors.FailedRecords += rv.FailedRecords
+		r.Errors.RecordErrors += rv.RecordErrors
+		r.Errors.RateLimited429 += rv.RateLimited429
+		r.Errors.ServerErrors5xx += rv.ServerErrors5xx
+		r.Errors.RejectedRecords += rv.Rejected
+		if rv.Status != "success" {
+			r.Errors.FailedVaults = append(r.Errors.FailedVaults, rv.Name)
+		}
+		if rv.ErrorLog != "" {
+			r.Artifacts.ErrorLogs = append(r.Artifacts.ErrorLogs, rv.ErrorLog)
+		}
+	}
+	if totalElapsed > 0 {
+		r.Totals.Throughput = float64(r.Totals.RecordsLoaded) / totalElapsed.Seconds()
+	}
+	if config.Crosswalk != nil {
+		r.Errors.CrosswalkFailures = atomic.LoadInt64(&config.Crosswalk.Failed)
+	}
+	if config.Rejects != nil && r.Errors.RejectedRecords > 0 {
+		r.Artifacts.RejectFile = config.Rejects.Path
+	}
+	if conflictsFound && config.Dedup != nil {
+		r.Artifacts.ConflictReport = config.Dedup.ReportPath
+	}
+}
+
+// vaultStatus classifies one vault's outcome for the run report
+func vaultStatus(m *Metrics) string {
+	switch {
+	case m.Aborted:
+		return "aborted"
+	case m.Error != "":
+		return "failed"
+	case atomic.LoadInt64(&m.FailedBatches) > 0, atomic.LoadInt64(&m.RecordErrors) > 0:
+		return "partial_failure"
+	}
+	return "success"
+}
+
+// loadOutcome maps the vault results of a load to the process exit code
+func loadOutcome(allMetrics []*Metrics) (int, string) {
+	var failed []string
+	for _, m := range allMetrics {
+		switch vaultStatus(m) {
+		case "aborted":
+			return exitFatal, fmt.Sprintf("%s aborted: %s", m.VaultName, m.Error)
+		case "failed":
+			failed = append(failed, fmt.Sprintf("%s: %s", m.VaultName, m.Error))
+		case "partial_failure":
+			failed = append(failed, fmt.Sprintf("%s: %d failed batches, %d records rejected by the vault",
+				m.VaultName, atomic.LoadInt64(&m.FailedBatches), atomic.LoadInt64(&m.RecordErrors)))
+		}
+	}
+	if len(failed) > 0 {
+		return exitPartialFailure, strings.Join(failed, "; ")
+	}
+	return exitSuccess, ""
+}
+
+// Finish records the exit status, appends the run to the history file and writes the report (failures to write are reported, not fatal)
+func (r *RunReport) Finish(code int, reason string) {
+	if r == nil {
+		return
+	}
+	r.FinishedAt = time.Now()
+	r.DurationSeconds = r.FinishedAt.Sub(r.StartedAt).Seconds()
+	r.ExitCode = code
+	switch code {
+	case exitSuccess:
+		r.Status = "success"
+	case exitPartialFailure:
+		r.Status = "partial_failure"
+	default:
+		r.Status = "fatal_error"
+	}
+	if code != exitSuccess {
+		r.FatalError = reason
+	}
+	if r.Vaults == nil {
+		r.Vaults = []*ReportVault{}
+	}
+	if r.Artifacts.ErrorLogs == nil {
+		r.Artifacts.ErrorLogs = []string{}
+	}
+	if r.Errors.FailedVaults == nil {
+		r.Errors.FailedVaults = []string{}
+	}
+
+	// Runs that never got as far as loading their configuration are not worth comparing
+	if r.HistoryPath != "" && r.Config != nil {
+		if id, err := appendRunHistory(r.HistoryPath, r); err != nil {
+			fmt.Printf("⚠️  Failed to record run history: %v\n", err)
+		} else {
+			fmt.Printf("🗂️  Run recorded in history: %s (%s)\n", id, r.HistoryPath)
+		}
+	}
+
+	if r.Path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		fmt.Printf("⚠️  Failed to encode run report: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(r.Path, append(data, '\n'), 0600); err != nil {
+		fmt.Printf("⚠️  Failed to write run report: %v\n", err)
+		return
+	}
+	fmt.Printf("📄 Run report: %s (%s, exit code %d)\n", r.Path, r.Status, code)
+}
+
+// finishOnce makes the first outcome final when a signal arrives while main is finishing
+var finishOnce sync.Once
+
+// finishRun records the outcome in the run report, sends the final webhook notification and
+// flushes traces; only the first call has any effect
+func finishRun(code int, reason string) {
+	finishOnce.Do(func() {
+		runReport.Finish(code, reason)
+		notifier.Finish(code, reason, runReport)
+		tracer.Shutdown(reason)
+	})
+}
+
+// Cleanups registered by main (PID file, control socket, sinks, sources). os.Exit skips
+// deferred calls, so exitRun runs these itself; main runs them on return.
+var (
+	cleanupMu sync.Mutex
+	cleanups  []func()
+)
+
+// atExit registers fn to run when the run ends, in reverse order of registration
+func atExit(fn func()) {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+	cleanups = append(cleanups, fn)
+}
+
+// runCleanups runs and clears the registered cleanups (safe to call twice)
+func runCleanups() {
+	cleanupMu.Lock()
+	pending := cleanups
+	cleanups = nil
+	cleanupMu.Unlock()
+	for i := len(pending) - 1; i >= 0; i-- {
+		pending[i]()
+	}
+}
+
+// exitRun finishes the run, releases what main registered with atExit and exits with code
+func exitRun(code int, reason string) {
+	finishRun(code, reason)
+	runCleanups()
+	flushLogs()
+	os.Exit(code)
+}
+
+// fatalf prints an error that stops the run, records it in the run report and exits with exitFatal
+func fatalf(format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Printf("❌ %s\n", message)
+	exitRun(exitFatal, message)
+}
+
+// Notifications: webhooks posted when the run starts, periodically while it loads, when it
+// completes and when it stops on a fatal error. Deliveries run in order in the background;
+// the final notification is awaited before the process exits.
+
+// notifier is nil unless webhooks are configured; every method is nil-safe
+var notifier *Notifier
+
+// webhookEvents and webhookFormats are the accepted events and payload templates
+var (
+	webhookEvents  = []string{"start", "progress", "complete", "fatal"}
+	webhookFormats = []string{"json", "slack"}
+)
+
+const (
+	defaultWebhookTimeout          = 10 * time.Second
+	defaultWebhookRetries          = 3
+	defaultWebhookProgressInterval = 30 * time.Minute
+)
+
+// WebhookPayload is the generic JSON template; the slack template posts Message as {"text": ...}
+type WebhookPayload struct {
+	Event     string           `json:"event"` // start, progress, complete or fatal
+	Timestamp time.Time        `json:"timestamp"`
+	Host      string           `json:"host"`
+	PID       int              `json:"pid"`
+	Mode      string           `json:"mode"`
+	Message   string           `json:"message"`            // One-line summary (the Slack text)
+	Config    *ReportConfig    `json:"config,omitempty"`   // start
+	Source    *ReportSource    `json:"source,omitempty"`   // start
+	Progress  *WebhookProgress `json:"progress,omitempty"` // progress
+	Report    *RunReport       `json:"report,omitempty"`   // complete and fatal: the JSON run report
+}
+
+// WebhookProgress summarizes the vaults loaded so far
+type WebhookProgress struct {
+	ElapsedSeconds float64                `json:"elapsed_seconds"`
+	RecordsPlanned int64                  `json:"records_planned"`
+	RecordsLoaded  int64                  `json:"records_loaded"`
+	FailedBatches  int64                  `json:"failed_batches"`
+	Vaults         []WebhookVaultProgress `json:"vaults"`
+}
+
+// WebhookVaultProgress is one vault in a progress notification
+type WebhookVaultProgress struct {
+	Vault          string  `json:"vault"`
+	Status         string  `json:"status"` // running or done
+	RecordsPlanned int64   `json:"records_planned"`
+	RecordsLoaded  int64   `json:"records_loaded"`
+	FailedBatches  int64   `json:"failed_batches"`
+	RecordsPerSec  float64 `json:"records_per_sec"`
+}
+
+// webhookTarget is a validated webhook
+type webhookTarget struct {
+	url     string
+	label   string // Scheme and host only: webhook URLs often embed a secret
+	format  string
+	events  map[string]bool
+	headers map[string]string
+	retries int
+	client  *http.Client
+}
+
+// webhookDelivery is one queued notification for one webhook
+type webhookDelivery struct {
+	target *webhookTarget
+	event  string
+	body   []byte
+}
+
+// notifiedVault is a vault whose progress is reported
+type notifiedVault struct {
+	metrics *Metrics
+	running bool
+}
+
+// Notifier renders and delivers webhook notifications
+type Notifier struct {
+	Sent     int64
+	Failed   int64
+	targets  []*webhookTarget
+	interval time.Duration
+	host     string
+	mode     string
+	started  time.Time
+	mu       sync.Mutex
+	vaults   []notifiedVault
+	queue    chan webhookDelivery
+	done     chan struct{}
+	stop     chan struct{}
+	ticking  sync.WaitGroup // Progress ticker; Finish waits for it before closing queue
+	once     sync.Once
+}
+
+// NewNotifier validates the webhooks and starts the delivery goroutine
+func NewNotifier(cfg NotificationsConfig) (*Notifier, error) {
+	n := &Notifier{
+		interval: defaultWebhookProgressInterval,
+		started:  time.Now(),
+		queue:    make(chan webhookDelivery, 64),
+		done:     make(chan struct{}),
+		stop:     make(chan struct{}),
+	}
+	if cfg.ProgressIntervalMinutes < 0 {
+		return nil, fmt.Errorf("progress_interval_minutes must not be negative")
+	}
+	if cfg.ProgressIntervalMinutes > 0 {
+		n.interval = time.Duration(cfg.ProgressIntervalMinutes) * time.Minute
+	}
+	n.host, _ = os.Hostname()
+
+	for i, hook := range cfg.Webhooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("webhook %d: url must be an http(s) URL", i+1)
+		}
+		target := &webhookTarget{
+			url:     hook.URL,
+			label:   u.Scheme + "://" + u.Host,
+			format:  cmp.Or(hook.Format, "json"),
+			events:  map[string]bool{},
+			headers: hook.Headers,
+			retries: defaultWebhookRetries,
+			client:  &http.Client{Timeout: defaultWebhookTimeout},
+		}
+		if !slices.Contains(webhookFormats, target.format) {
+			return nil, fmt.Errorf("webhook %d: unsupported format %q (use %s)", i+1, hook.Format, strings.Join(webhookFormats, ", "))
+		}
+		events := hook.Events
+		if len(events) == 0 {
+			events = webhookEvents
+		}
+		for _, event := range events {
+			if !slices.Contains(webhookEvents, event) {
+				return nil, fmt.Errorf("webhook %d: unknown event %q (use %s)", i+1, event, strings.Join(webhookEvents, ", "))
+			}
+			target.events[event] = true
+		}
+		if hook.TimeoutSeconds < 0 || hook.Retries < 0 {
+			return nil, fmt.Errorf("webhook %d: timeout_seconds and retries must not be negative", i+1)
+		}
+		if hook.TimeoutSeconds > 0 {
+			target.client.Timeout = time.Duration(hook.TimeoutSeconds) * time.Second
 		}
-		if s.Config.StartRecord > 0 {
-			query += fmt.Sprintf(" OFFSET %d", s.Config.StartRecord)
+		if hook.Retries > 0 {
+			target.retries = hook.Retries
 		}
-	} else if maxRecords > 0 {
-		// Simple max records mode
-		query += fmt.Sprintf(" LIMIT %d", maxRecords)
+		n.targets = append(n.targets, target)
 	}
 
-	// Configure fetch size for this session if specified
-	// This controls how many rows are fetched from Snowflake to the client in each network round trip
-	if s.Config.FetchSize > 0 {
-		if s.Config.StartRecord > 0 || s.Config.EndRecord > 0 {
-			fmt.Printf("📊 Querying Snowflake for %s data (records %d-%d, network fetch size %d)...\n",
-				vaultConfig.Name, s.Config.StartRecord, s.Config.EndRecord, s.Config.FetchSize)
-		} else {
-			fmt.Printf("📊 Querying Snowflake for %s data (cursor mode: network fetch size %d)...\n",
-				vaultConfig.Name, s.Config.FetchSize)
-		}
-		// Set session parameter for fetch size
-		_, err := s.DB.Exec(fmt.Sprintf("ALTER SESSION SET ROWS_PER_RESULTSET = %d", s.Config.FetchSize))
-		if err != nil {
-			fmt.Printf("⚠️  Warning: Could not set fetch size: %v (continuing anyway)\n", err)
+	go n.deliverQueued()
+	return n, nil
+}
+
+// Start sends the start notification and begins periodic progress notifications
+func (n *Notifier) Start(mode string, report *RunReport) {
+	if n == nil {
+		return
+	}
+	n.mode = mode
+	payload := n.payload("start", fmt.Sprintf("🚀 Skyflow loader started on %s (pid %d): %s", n.host, os.Getpid(), mode))
+	if report != nil {
+		payload.Config, payload.Source = report.Config, report.Source
+		if report.Config != nil && report.Source != nil {
+			payload.Message += fmt.Sprintf(" of %d vault(s) from %s", len(report.Config.Vaults), report.Source.Type)
 		}
-	} else {
-		if s.Config.StartRecord > 0 || s.Config.EndRecord > 0 {
-			fmt.Printf("📊 Querying Snowflake for %s data (records %d-%d)...\n",
-				vaultConfig.Name, s.Config.StartRecord, s.Config.EndRecord)
-		} else {
-			fmt.Printf("📊 Querying Snowflake for %s data...\n", vaultConfig.Name)
+	}
+	n.send(payload)
+
+	n.ticking.Add(1)
+	go func() {
+		defer n.ticking.Done()
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.stop:
+				return
+			case <-ticker.C:
+				n.sendProgress()
+			}
 		}
+	}()
+}
+
+// Track adds a vault to progress notifications (nil-safe)
+func (n *Notifier) Track(m *Metrics) {
+	if n == nil {
+		return
 	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.vaults = append(n.vaults, notifiedVault{metrics: m, running: true})
+}
 
-	// Execute query ONCE - this creates a cursor on Snowflake
-	fmt.Printf("  🔍 Executing SQL query on Snowflake...\n")
-	queryStart := time.Now()
-	rows, err := s.DB.Query(query)
-	queryDuration := time.Since(queryStart)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute query: %w", err)
+// Done marks a tracked vault as finished (nil-safe)
+func (n *Notifier) Done(m *Metrics) {
+	if n == nil {
+		return
 	}
-	defer rows.Close()
-	fmt.Printf("  ✅ Query executed successfully (%.2f seconds)\n", queryDuration.Seconds())
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i := range n.vaults {
+		if n.vaults[i].metrics == m {
+			n.vaults[i].running = false
+		}
+	}
+}
 
-	// Pre-allocate slice
-	capacity := maxRecords
-	if capacity <= 0 {
-		capacity = 10000
+// sendProgress reports every tracked vault (nothing before the first vault starts)
+func (n *Notifier) sendProgress() {
+	n.mu.Lock()
+	vaults := slices.Clone(n.vaults)
+	n.mu.Unlock()
+	if len(vaults) == 0 {
+		return
 	}
-	records := make([]Record, 0, capacity)
 
-	// Stream results via cursor - the driver fetches in batches behind the scenes
-	fmt.Printf("  📥 Starting to fetch rows from result set...\n")
-	recordCount := 0
-	lastLog := time.Now()
-	for rows.Next() {
-		var value, token string
-		if err := rows.Scan(&value, &token); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
+	progress := &WebhookProgress{ElapsedSeconds: time.Since(n.started).Seconds(), Vaults: []WebhookVaultProgress{}}
+	var current string
+	for _, v := range vaults {
+		m := v.metrics
+		vp := WebhookVaultProgress{
+			Vault:          m.VaultName,
+			Status:         "done",
+			RecordsPlanned: atomic.LoadInt64(&m.PlannedRecords),
+			RecordsLoaded:  atomic.LoadInt64(&m.TotalRecords),
+			FailedBatches:  atomic.LoadInt64(&m.FailedBatches),
+		}
+		if v.running {
+			vp.Status = "running"
+			current = m.VaultName
+			if elapsed := time.Since(m.StartTime).Seconds(); elapsed > 0 {
+				vp.RecordsPerSec = float64(vp.RecordsLoaded) / elapsed
+			}
 		}
+		progress.RecordsPlanned += vp.RecordsPlanned
+		progress.RecordsLoaded += vp.RecordsLoaded
+		progress.FailedBatches += vp.FailedBatches
+		progress.Vaults = append(progress.Vaults, vp)
+	}
 
-		if value != "" && token != "" {
-			records = append(records, Record{
-				Value: value,
-				Token: token,
-			})
-			recordCount++
+	message := fmt.Sprintf("⏳ Skyflow loader on %s: %s/%s records loaded after %s | %s failed batches",
+		n.host, formatNumber(int(progress.RecordsLoaded)), formatNumber(int(progress.RecordsPlanned)),
+		time.Since(n.started).Round(time.Second), formatNumber(int(progress.FailedBatches)))
+	if current != "" {
+		message += " | loading " + current
+	}
+	payload := n.payload("progress", message)
+	payload.Progress = progress
+	n.send(payload)
+}
 
-			// Progress update - more frequent for smaller datasets
-			progressInterval := 100000
-			if maxRecords > 0 && maxRecords < 100000 {
-				progressInterval = maxRecords / 10 // Log every 10%
-				if progressInterval < 100 {
-					progressInterval = 100 // At least every 100 records
-				}
+// Finish sends the complete (or, for exitFatal, fatal) notification with the run report and waits
+// for every queued notification to be delivered; safe to call more than once
+func (n *Notifier) Finish(code int, reason string, report *RunReport) {
+	if n == nil {
+		return
+	}
+	n.once.Do(func() {
+		// A progress notification already being sent must be queued before the queue closes
+		close(n.stop)
+		n.ticking.Wait()
+		elapsed := time.Since(n.started).Round(time.Second)
+		var payload WebhookPayload
+		if code == exitFatal {
+			payload = n.payload("fatal", fmt.Sprintf("❌ Skyflow loader on %s failed after %s: %s", n.host, elapsed, reason))
+		} else {
+			icon, status := "✅", "success"
+			if code != exitSuccess {
+				icon, status = "⚠️", "partial failure"
 			}
-
-			// Also log every 5 seconds regardless of record count
-			if recordCount%progressInterval == 0 || time.Since(lastLog) > 5*time.Second {
-				fmt.Printf("  📥 Fetched %d records from Snowflake so far...\n", recordCount)
-				lastLog = time.Now()
+			message := fmt.Sprintf("%s Skyflow loader on %s finished (%s) in %s", icon, n.host, status, elapsed)
+			if report != nil && report.Mode != "verify" && report.Mode != "reconcile" {
+				message += fmt.Sprintf(" | %s records loaded | %s failed batches",
+					formatNumber(int(report.Totals.RecordsLoaded)), formatNumber(int(report.Errors.FailedBatches)))
 			}
+			if reason != "" {
+				message += " | " + reason
+			}
+			payload = n.payload("complete", message)
 		}
+		payload.Report = report
+		n.send(payload)
 
-		if maxRecords > 0 && recordCount >= maxRecords {
-			break
+		close(n.queue)
+		<-n.done
+		fmt.Printf("🔔 Webhooks: %d notifications sent, %d failed\n", atomic.LoadInt64(&n.Sent), atomic.LoadInt64(&n.Failed))
+	})
+}
+
+func (n *Notifier) payload(event, message string) WebhookPayload {
+	return WebhookPayload{Event: event, Timestamp: time.Now(), Host: n.host, PID: os.Getpid(), Mode: n.mode, Message: redactPII(message)}
+}
+
+// send renders the payload for every webhook subscribed to the event and queues it; progress
+// notifications are dropped rather than delaying the load when deliveries fall behind
+func (n *Notifier) send(payload WebhookPayload) {
+	for _, target := range n.targets {
+		if !target.events[payload.Event] {
+			continue
+		}
+		var body []byte
+		var err error
+		if target.format == "slack" {
+			body, err = json.Marshal(map[string]string{"text": payload.Message})
+		} else {
+			body, err = json.Marshal(payload)
+		}
+		if err != nil {
+			atomic.AddInt64(&n.Failed, 1)
+			continue
+		}
+		delivery := webhookDelivery{target: target, event: payload.Event, body: body}
+		if payload.Event == "progress" {
+			select {
+			case n.queue <- delivery:
+			default:
+				atomic.AddInt64(&n.Failed, 1)
+			}
+			continue
 		}
+		n.queue <- delivery
 	}
-	fmt.Printf("  ✅ Finished fetching rows from Snowflake\n")
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+// deliverQueued posts queued notifications in order until the queue is closed
+func (n *Notifier) deliverQueued() {
+	defer close(n.done)
+	for d := range n.queue {
+		if err := d.target.post(d.body); err != nil {
+			atomic.AddInt64(&n.Failed, 1)
+			logger.Warn(fmt.Sprintf("Webhook %s: %s notification failed: %v", d.target.label, d.event, err),
+				"component", "notify", "webhook", d.target.label, "event", d.event, "error", err)
+			continue
+		}
+		atomic.AddInt64(&n.Sent, 1)
 	}
-
-	fmt.Printf("✅ Retrieved %d records from Snowflake\n", len(records))
-	return records, nil
 }
 
-func getDataColumnName(column string) string {
-	switch column {
-	case "name":
-		return "full_name"
-	case "id":
-		return "id"
-	case "dob":
-		return "dob"
-	case "ssn":
-		return "ssn"
-	default:
-		return column
+// post sends one notification, retrying network errors, 429 and 5xx with exponential backoff
+func (t *webhookTarget) post(body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= t.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<uint(attempt-1)) * time.Second)
+		}
+		req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "skyflow-byot-loader")
+		for k, v := range t.headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := t.client.Do(req)
+		if err != nil {
+			// The URL may hold a secret: report the failure without it
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			lastErr = err
+			continue
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return nil
+		}
+		lastErr = fmt.Errorf("status %d", resp.StatusCode)
+		if resp.StatusCode != 429 && resp.StatusCode < 500 {
+			return lastErr
+		}
 	}
+	return fmt.Errorf("%w (after %d attempts)", lastErr, t.retries+1)
 }
 
-func getTokenColumnName(column string) string {
-	return getDataColumnName(column) + "_token"
-}
+// Run history (opt-in): with -history-file, every run that got as far as loading its configuration
+// appends its run report to a JSON Lines file; the history subcommand lists, shows and diffs recorded runs.
 
-// Create BYOT payload (optimized with buffer pool)
-func createBYOTPayload(records []Record, vaultConfig VaultConfig, config *Config, metrics *Metrics) ([]byte, error) {
-	payloadStart := time.Now()
+// defaultHistoryFile is where the history subcommand looks when -file is not given
+const defaultHistoryFile = "skyflow-loader-history.jsonl"
 
-	recordsJSON := make([]map[string]interface{}, 0, len(records))
+// HistoryEntry is one line of the history file: the run report with a run ID
+type HistoryEntry struct {
+	ID string `json:"id"`
+	*RunReport
+}
 
-	// Pre-allocate string builders for suffix concatenation (if enabled)
-	var valueBuilder, tokenBuilder strings.Builder
+// runHistoryID identifies a run by its start time and PID (unique across parallel processes)
+func runHistoryID(r *RunReport) string {
+	return fmt.Sprintf("%s-%d", r.StartedAt.Format("20060102-150405"), r.Host.PID)
+}
 
-	for _, record := range records {
-		value := record.Value
-		token := record.Token
+// appendRunHistory appends the finished report to the history file (locked, so parallel loader
+// processes can share one file)
+func appendRunHistory(path string, r *RunReport) (string, error) {
+	entry := HistoryEntry{ID: runHistoryID(r), RunReport: r}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode history entry: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		return "", fmt.Errorf("failed to lock history file: %w", err)
+	}
+	defer syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return "", fmt.Errorf("failed to write history file: %w", err)
+	}
+	return entry.ID, nil
+}
 
-		if config.AppendSuffix {
-			suffixStart := time.Now()
-			dataSuffix := generateUniqueSuffix()
-			tokenSuffix := generateUniqueSuffix()
-
-			// Use strings.Builder to avoid multiple string allocations
-			valueBuilder.Reset()
-			valueBuilder.Grow(len(record.Value) + 1 + len(dataSuffix))
-			valueBuilder.WriteString(record.Value)
-			valueBuilder.WriteByte('_')
-			valueBuilder.WriteString(dataSuffix)
-			value = valueBuilder.String()
-
-			tokenBuilder.Reset()
-			tokenBuilder.Grow(len(record.Token) + 1 + len(tokenSuffix))
-			tokenBuilder.WriteString(record.Token)
-			tokenBuilder.WriteByte('_')
-			tokenBuilder.WriteString(tokenSuffix)
-			token = tokenBuilder.String()
+// loadRunHistory reads every run in the history file, oldest first (unreadable lines are skipped)
+func loadRunHistory(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // No runs recorded yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
 
-			metrics.AddTime("suffix_gen", time.Since(suffixStart))
+	var entries []HistoryEntry
+	skipped := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(line, &entry); err != nil || entry.RunReport == nil || entry.ID == "" {
+			skipped++
+			continue
 		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	if skipped > 0 {
+		fmt.Printf("⚠️  Skipped %d unreadable history entries\n", skipped)
+	}
+	return entries, nil
+}
 
-		recordsJSON = append(recordsJSON, map[string]interface{}{
-			"fields": map[string]string{
-				vaultConfig.Column: value,
-			},
-			"tokens": map[string]string{
-				vaultConfig.Column: token,
-			},
-		})
+// findRun resolves a run reference: "last", "last-N" (N runs before the last), an ID or a unique ID prefix
+func findRun(entries []HistoryEntry, ref string) (*HistoryEntry, error) {
+	if ref == "last" || strings.HasPrefix(ref, "last-") {
+		back := 0
+		if ref != "last" {
+			n, err := strconv.Atoi(strings.TrimPrefix(ref, "last-"))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid run reference %q (use last, last-N or a run ID)", ref)
+			}
+			back = n
+		}
+		if back >= len(entries) {
+			return nil, fmt.Errorf("history has only %d runs", len(entries))
+		}
+		return &entries[len(entries)-1-back], nil
+	}
+	var match *HistoryEntry
+	for i := range entries {
+		if entries[i].ID == ref {
+			return &entries[i], nil
+		}
+		if strings.HasPrefix(entries[i].ID, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("run ID prefix %q is ambiguous", ref)
+			}
+			match = &entries[i]
+		}
 	}
+	if match == nil {
+		return nil, fmt.Errorf("no run %q in history", ref)
+	}
+	return match, nil
+}
 
-	payload := map[string]interface{}{
-		"records":         recordsJSON,
-		"continueOnError": true,
-		"tokenization":    true,
-		"byot":            "ENABLE",
+// runHistory implements the history subcommand
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	historyFile := fs.String("file", defaultHistoryFile, "Run history file")
+	limit := fs.Int("n", 20, "list: number of most recent runs to show (0 = all)")
+	asJSON := fs.Bool("json", false, "show: print the recorded entry as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: %s history [flags] <command>
+
+Commands:
+  list                        Recent runs with their settings and results (default)
+  show <run>                  One run's settings, totals and per-vault results
+  diff <run> <run>            Compare settings, throughput, latency and errors of two runs
+
+Runs are referenced by ID, unique ID prefix, "last" or "last-N" (N runs before the last).
+
+Flags:
+`, os.Args[0])
+		fs.PrintDefaults()
 	}
+	fs.Parse(args)
+	command := "list"
+	if fs.NArg() > 0 {
+		command = fs.Arg(0)
+		fs.Parse(fs.Args()[1:]) // Flags may also follow the command
+	}
+	refs := fs.Args()
 
-	// Add upsert parameter if enabled (upsert on the column being inserted)
-	if config.Upsert {
-		payload["upsert"] = vaultConfig.Column
+	entries, err := loadRunHistory(*historyFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitFatal
+	}
+	switch command {
+	case "list":
+		printHistoryList(entries, *limit)
+	case "show":
+		if len(refs) != 1 {
+			fmt.Printf("❌ history show needs one run\n")
+			return exitFatal
+		}
+		run, err := findRun(entries, refs[0])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFatal
+		}
+		if *asJSON {
+			data, _ := json.MarshalIndent(run, "", "  ")
+			fmt.Println(string(data))
+		} else {
+			printHistoryRun(run)
+		}
+	case "diff":
+		if len(refs) != 2 {
+			fmt.Printf("❌ history diff needs two runs\n")
+			return exitFatal
+		}
+		a, err := findRun(entries, refs[0])
+		if err == nil {
+			var b *HistoryEntry
+			if b, err = findRun(entries, refs[1]); err == nil {
+				printHistoryDiff(a, b)
+			}
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFatal
+		}
+	default:
+		fmt.Printf("❌ Unknown history command %q\n", command)
+		fs.Usage()
+		return exitFatal
+	}
+	return exitSuccess
+}
+
+// historyDuration formats seconds as a rounded duration
+func historyDuration(seconds float64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%.1fs", seconds)
 	}
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
 
-	metrics.AddTime("payload_creation", time.Since(payloadStart))
+// printHistoryList prints the most recent runs, oldest first
+func printHistoryList(entries []HistoryEntry, limit int) {
+	if len(entries) == 0 {
+		fmt.Println("No runs recorded yet (start loads with -history-file " + defaultHistoryFile + " to record them)")
+		return
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	fmt.Printf("%-22s %-16s %-10s %-16s %9s %6s %12s %9s %6s %5s %7s\n",
+		"RUN", "STARTED", "MODE", "STATUS", "DURATION", "VAULTS", "LOADED", "REC/S", "BATCH", "CONC", "FAILED")
+	for _, e := range entries {
+		batch, concurrency := "-", "-"
+		if c := e.Config; c != nil {
+			batch, concurrency = strconv.Itoa(c.BatchSize), strconv.Itoa(c.MaxConcurrency)
+		}
+		fmt.Printf("%-22s %-16s %-10s %-16s %9s %6d %12s %9s %6s %5s %7s\n",
+			e.ID, e.StartedAt.Local().Format("2006-01-02 15:04"), e.Mode, e.Status, historyDuration(e.DurationSeconds),
+			len(e.Vaults), formatNumber(int(e.Totals.RecordsLoaded)), formatNumber(int(e.Totals.Throughput)),
+			batch, concurrency, formatNumber(int(e.Errors.FailedBatches)))
+	}
+}
 
-	// Use buffer pool for JSON marshaling
-	jsonStart := time.Now()
-	buf := bufferPool.Get().(*bytes.Buffer)
-	buf.Reset()
-	defer bufferPool.Put(buf)
+// historySettings are the settings shown and compared for a run
+func historySettings(r *RunReport) [][2]string {
+	c := r.Config
+	if c == nil {
+		return nil
+	}
+	var vaults []string
+	for _, v := range c.Vaults {
+		vaults = append(vaults, v.Name)
+	}
+	source := ""
+	if r.Source != nil {
+		source = r.Source.Type
+	}
+	return [][2]string{
+		{"Source", source},
+		{"Vault URL", c.VaultURL},
+		{"Vaults", strings.Join(vaults, ", ")},
+		{"Batch size", strconv.Itoa(c.BatchSize)},
+		{"Concurrency", strconv.Itoa(c.MaxConcurrency)},
+		{"Base delay (ms)", strconv.FormatInt(c.BaseDelayMs, 10)},
+		{"Max records", strconv.Itoa(c.MaxRecords)},
+		{"Compression", cmp.Or(c.Compression, "none")},
+		{"Upsert", strconv.FormatBool(c.Upsert)},
+		{"Append suffix", strconv.FormatBool(c.AppendSuffix)},
+		{"Dedup", cmp.Or(c.Dedup, "off")},
+	}
+}
 
-	encoder := json.NewEncoder(buf)
-	if err := encoder.Encode(payload); err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+// printHistoryRun prints one run's settings, totals and per-vault results
+func printHistoryRun(e *HistoryEntry) {
+	fmt.Printf("Run %s\n", e.ID)
+	fmt.Printf("  Mode: %s | Status: %s (exit code %d) | Started: %s | Duration: %s\n",
+		e.Mode, e.Status, e.ExitCode, e.StartedAt.Local().Format("2006-01-02 15:04:05"), historyDuration(e.DurationSeconds))
+	fmt.Printf("  Host: %s (pid %d, %d CPUs)\n", e.Host.Hostname, e.Host.PID, e.Host.CPUs)
+	if e.FatalError != "" {
+		fmt.Printf("  Error: %s\n", e.FatalError)
 	}
 
-	// Copy buffer content
-	jsonData := make([]byte, buf.Len())
-	copy(jsonData, buf.Bytes())
+	if settings := historySettings(e.RunReport); len(settings) > 0 {
+		fmt.Printf("\nSETTINGS:\n")
+		for _, s := range settings {
+			fmt.Printf("  %-18s %s\n", s[0]+":", s[1])
+		}
+	}
 
-	metrics.AddTime("json_serialization", time.Since(jsonStart))
+	fmt.Printf("\nTOTALS:\n")
+	fmt.Printf("  Records loaded:    %s of %s\n", formatNumber(int(e.Totals.RecordsLoaded)), formatNumber(int(e.Totals.RecordsPlanned)))
+	fmt.Printf("  Throughput:        %s records/sec\n", formatNumber(int(e.Totals.Throughput)))
+	fmt.Printf("  Batches:           %s (%s failed, %s records)\n",
+		formatNumber(int(e.Errors.TotalBatches)), formatNumber(int(e.Errors.FailedBatches)), formatNumber(e.Errors.FailedRecords))
+	fmt.Printf("  429s / 5xx:        %s / %s\n", formatNumber(int(e.Errors.RateLimited429)), formatNumber(int(e.Errors.ServerErrors5xx)))
+
+	if len(e.Vaults) > 0 {
+		fmt.Printf("\nVAULTS:               %-16s %12s %9s %9s %7s %8s %8s %8s %8s\n",
+			"Status", "Loaded", "Rec/s", "Batches", "Failed", "p50 ms", "p99 ms", "p99.9 ms", "429s")
+		for _, v := range e.Vaults {
+			all := v.Latency["all"]
+			fmt.Printf("  %-20s %-16s %12s %9s %9s %7s %8.1f %8.1f %8.1f %8s\n",
+				v.Name, v.Status, formatNumber(int(v.RecordsLoaded)), formatNumber(int(v.Throughput)),
+				formatNumber(int(v.Batches.Total)), formatNumber(int(v.Batches.Failed)),
+				all.P50, all.P99, all.P999, formatNumber(int(v.RateLimited429)))
+		}
+	}
+}
 
-	return jsonData, nil
+// historyChange formats the relative change from a to b
+func historyChange(a, b float64) string {
+	switch {
+	case a == b:
+		return ""
+	case a == 0:
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", (b-a)/a*100)
 }
 
-// Send batch to Skyflow (optimized with shared HTTP client)
-func sendBatch(client *http.Client, config *Config, vaultConfig VaultConfig, apiURL string, batch []Record, batchNum int, metrics *Metrics) error {
+// printHistoryDiff compares the settings, results and per-vault profiles of two runs
+func printHistoryDiff(a, b *HistoryEntry) {
+	fmt.Printf("Comparing A = %s (%s, %s) with B = %s (%s, %s)\n", a.ID, a.Mode, a.Status, b.ID, b.Mode, b.Status)
 
-	// Base delay
-	if config.BaseRequestDelay > 0 {
-		delayStart := time.Now()
-		time.Sleep(config.BaseRequestDelay)
-		metrics.AddTime("base_delay", time.Since(delayStart))
+	row := func(label string, va, vb float64, format func(float64) string) {
+		fmt.Printf("  %-34s %14s %14s %10s\n", label, format(va), format(vb), historyChange(va, vb))
 	}
-
-	// Create payload
-	payload, err := createBYOTPayload(batch, vaultConfig, config, metrics)
-	if err != nil {
-		return fmt.Errorf("failed to create payload: %w", err)
+	count := func(v float64) string { return formatNumber(int(v)) }
+	ms := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	seconds := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	settingsA, settingsB := historySettings(a.RunReport), historySettings(b.RunReport)
+	if len(settingsA) > 0 && len(settingsB) > 0 {
+		fmt.Printf("\n%-36s %-30s %s\n", "SETTINGS:", "A", "B")
+		for i := range settingsA {
+			line := fmt.Sprintf("  %-34s %-30s %s", settingsA[i][0], settingsA[i][1], settingsB[i][1])
+			if settingsA[i][1] != settingsB[i][1] {
+				line = fmt.Sprintf("%-98s *", line)
+			}
+			fmt.Println(line)
+		}
 	}
 
-	// Retry logic with exponential backoff
-	maxRetries := 3
-	hadRetry := false
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		req, err := http.NewRequest("POST", apiURL, bytes.NewReader(payload))
-		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+	fmt.Printf("\n%-36s %14s %14s %10s\n", "RESULTS:", "A", "B", "Change")
+	row("Duration (s)", a.DurationSeconds, b.DurationSeconds, seconds)
+	row("Records loaded", float64(a.Totals.RecordsLoaded), float64(b.Totals.RecordsLoaded), count)
+	row("Throughput (records/sec)", a.Totals.Throughput, b.Totals.Throughput, count)
+	row("Batches", float64(a.Errors.TotalBatches), float64(b.Errors.TotalBatches), count)
+	row("Failed batches", float64(a.Errors.FailedBatches), float64(b.Errors.FailedBatches), count)
+	row("Failed records", float64(a.Errors.FailedRecords), float64(b.Errors.FailedRecords), count)
+	row("429 responses", float64(a.Errors.RateLimited429), float64(b.Errors.RateLimited429), count)
+	row("5xx responses", float64(a.Errors.ServerErrors5xx), float64(b.Errors.ServerErrors5xx), count)
+	statuses := map[string]bool{}
+	for status := range a.Errors.FailedByStatus {
+		statuses[status] = true
+	}
+	for status := range b.Errors.FailedByStatus {
+		statuses[status] = true
+	}
+	for _, status := range slices.Sorted(maps.Keys(statuses)) {
+		label := "Failed with status " + status
+		if status == "0" {
+			label = "Failed (network error)"
 		}
+		row(label, float64(a.Errors.FailedByStatus[status]), float64(b.Errors.FailedByStatus[status]), count)
+	}
 
-		req.Header.Set("Authorization", "Bearer "+config.BearerToken)
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept-Encoding", "gzip")
-
-		// Track active requests
-		atomic.AddInt64(&metrics.ActiveRequests, 1)
-		atomic.AddInt64(&metrics.TotalRequests, 1)
-
-		apiStart := time.Now()
-		resp, err := client.Do(req)
-		apiDuration := time.Since(apiStart)
-		if resp != nil {
+	// Per-vault profiles for vaults present in both runs
+	vaultsB := map[string]*ReportVault{}
+	for _, v := range b.Vaults {
+		vaultsB[v.Name] = v
+	}
+	for _, va := range a.Vaults {
+		vb, ok := vaultsB[va.Name]
+		if !ok {
+			continue
+		}
+		fmt.Printf("\n%-36s %14s %14s %10s\n", strings.ToUpper(va.Name)+":", "A", "B", "Change")
+		row("Throughput (records/sec)", va.Throughput, vb.Throughput, count)
+		row("Records loaded", float64(va.RecordsLoaded), float64(vb.RecordsLoaded), count)
+		row("HTTP requests", float64(va.Requests), float64(vb.Requests), count)
+		row("Success after retry", float64(va.Batches.AfterRetry), float64(vb.Batches.AfterRetry), count)
+		row("Failed batches", float64(va.Batches.Failed), float64(vb.Batches.Failed), count)
+		row("429 responses", float64(va.RateLimited429), float64(vb.RateLimited429), count)
+		row("5xx responses", float64(va.ServerErrors5xx), float64(vb.ServerErrors5xx), count)
+		la, lb := va.Latency["all"], vb.Latency["all"]
+		row("Latency p50 (ms)", la.P50, lb.P50, ms)
+		row("Latency p90 (ms)", la.P90, lb.P90, ms)
+		row("Latency p99 (ms)", la.P99, lb.P99, ms)
+		row("Latency p99.9 (ms)", la.P999, lb.P999, ms)
+		row("Latency max (ms)", la.Max, lb.Max, ms)
+		for _, component := range timingComponents {
+			ta, tb := va.Timing[component], vb.Timing[component]
+			if ta > 0 || tb > 0 {
+				row("Time in "+component+" (s)", ta, tb, seconds)
+			}
 		}
+	}
+}
+
+// Clear vault table - delete all records
+func clearVaultTable(client *http.Client, config *Config, vaultConfig VaultConfig) error {
+	fmt.Printf("\n🗑️  Clearing %s vault...\n", vaultConfig.Name)
 
-		atomic.AddInt64(&metrics.ActiveRequests, -1)
-		metrics.AddTime("api_call", apiDuration)
+	baseURL := fmt.Sprintf("%s/v1/vaults/%s/%s", config.VaultURL, vaultConfig.ID, vaultConfig.TableName())
+	totalDeleted := 0
+	iteration := 0
+	fetchLimit := 100
+	batchSize := 100
 
-		// Track API latency for live metrics
-		latencyNanos := apiDuration.Nanoseconds()
-		atomic.AddInt64(&metrics.TotalAPILatency, latencyNanos)
+	for {
+		iteration++
 
-		// Update min latency (atomic compare-and-swap loop)
-		for {
-			oldMin := atomic.LoadInt64(&metrics.MinAPILatency)
-			if oldMin != 0 && oldMin <= latencyNanos {
-				break // Current min is smaller, no update needed
-			}
-			if atomic.CompareAndSwapInt64(&metrics.MinAPILatency, oldMin, latencyNanos) {
-				break
-			}
+		// Fetch records to delete
+		fetchURL := fmt.Sprintf("%s?offset=0&limit=%d", baseURL, fetchLimit)
+		bearerToken, err := config.Auth.Token()
+		if err != nil {
+			return fmt.Errorf("failed to obtain bearer token: %w", err)
 		}
 
-		// Update max latency (atomic compare-and-swap loop)
-		for {
-			oldMax := atomic.LoadInt64(&metrics.MaxAPILatency)
-			if oldMax >= latencyNanos {
-				break // Current max is larger, no update needed
-			}
-			if atomic.CompareAndSwapInt64(&metrics.MaxAPILatency, oldMax, latencyNanos) {
-				break
-			}
+		req, err := http.NewRequest("GET", fetchURL, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create fetch request: %w", err)
 		}
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
 
+		resp, err := client.Do(req)
 		if err != nil {
-			if attempt < maxRetries-1 {
-				hadRetry = true
-				retryStart := time.Now()
-				backoff := time.Duration(1<<uint(attempt)) * time.Second
-				time.Sleep(backoff)
-				metrics.AddTime("retry_delay", time.Since(retryStart))
-				continue
-			}
-			metrics.AddFailedBatch()
-			return fmt.Errorf("API request failed after retries: %w", err)
+			return fmt.Errorf("failed to fetch records: %w", err)
 		}
 
-		// Read body for error diagnostics
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 
-		if resp.StatusCode == 200 || resp.StatusCode == 201 {
-			// Track whether this was immediate success or after retry
-			if hadRetry {
-				atomic.AddInt64(&metrics.RetriedSuccesses, 1)
-			} else {
-				atomic.AddInt64(&metrics.ImmediateSuccesses, 1)
+		if resp.StatusCode == 404 {
+			// Table is empty
+			if iteration == 1 {
+				fmt.Printf("  ℹ️  %s vault is already empty\n", vaultConfig.Name)
 			}
-			metrics.AddSuccessfulBatch()
-			return nil
+			break
 		}
 
-		// Log non-success responses for diagnostics
-		if resp.StatusCode == 429 {
-			atomic.AddInt64(&metrics.RateLimited429, 1)
-			hadRetry = true
-			fmt.Printf("  ⚠️  Batch %d: Rate limited (429), retrying in %d seconds (attempt %d/%d)\n",
-				batchNum, 2<<uint(attempt), attempt+1, maxRetries)
-		} else if resp.StatusCode >= 500 {
-			atomic.AddInt64(&metrics.ServerErrors5xx, 1)
-			hadRetry = true
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("failed to fetch records: status %d", resp.StatusCode)
 		}
 
-		// Handle retryable errors
-		if resp.StatusCode == 429 || resp.StatusCode >= 500 {
-			if attempt < maxRetries-1 {
-				retryStart := time.Now()
-				backoff := time.Duration(2<<uint(attempt)) * time.Second
-				time.Sleep(backoff)
-				metrics.AddTime("retry_delay", time.Since(retryStart))
-				continue
-			}
-			// Max retries reached for retryable error
-			metrics.AddFailedBatch()
-			err := fmt.Errorf("API request failed with status %d after %d retries (body: %s)",
-				resp.StatusCode, maxRetries, string(bodyBytes))
-			// Log batch error for later review
-			metrics.BatchErrorsMutex.Lock()
-			metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
-				BatchNumber: batchNum,
-				Records:     batch,
-				Error:       err.Error(),
-				StatusCode:  resp.StatusCode,
-				Timestamp:   time.Now(),
-			})
-			metrics.BatchErrorsMutex.Unlock()
-			return err
+		// Parse response to get skyflow_ids
+		var fetchResp struct {
+			Records []struct {
+				Fields struct {
+					SkyflowID string `json:"skyflow_id"`
+				} `json:"fields"`
+			} `json:"records"`
 		}
 
-		// Non-retryable error (4xx other than 429)
-		metrics.AddFailedBatch()
-		fmt.Printf("  ❌ Batch %d: Non-retryable error %d (body: %s)\n",
-			batchNum, resp.StatusCode, string(bodyBytes))
-		err = fmt.Errorf("API request failed with non-retryable status %d", resp.StatusCode)
-		// Log batch error for later review
-		metrics.BatchErrorsMutex.Lock()
-		metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
-			BatchNumber: batchNum,
-			Records:     batch,
-			Error:       err.Error(),
-			StatusCode:  resp.StatusCode,
-			Timestamp:   time.Now(),
-		})
-		metrics.BatchErrorsMutex.Unlock()
-		return err
-	}
-
-	metrics.AddFailedBatch()
-	networkErr := fmt.Errorf("max retries exceeded due to network errors")
-	// Log batch error for later review
-	metrics.BatchErrorsMutex.Lock()
-	metrics.BatchErrors = append(metrics.BatchErrors, BatchError{
-		BatchNumber: batchNum,
-		Records:     batch,
-		Error:       networkErr.Error(),
-		StatusCode:  0,
-		Timestamp:   time.Now(),
-	})
-	metrics.BatchErrorsMutex.Unlock()
-	return networkErr
-}
-
-// Process a single vault
-func processVault(config *Config, vaultConfig VaultConfig, dataSource DataSource) *Metrics {
-	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
-	fmt.Printf("PROCESSING %s DATA\n", vaultConfig.Name)
-	fmt.Printf("%s\n", strings.Repeat("=", 80))
-
-	metrics := &Metrics{
-		VaultName: vaultConfig.Name,
-		StartTime: time.Now(),
-	}
-
-	// Read data from source
-	readStart := time.Now()
-	records, err := dataSource.ReadRecords(vaultConfig, config.MaxRecords)
-	if err != nil {
-		fmt.Printf("❌ Failed to read data: %v\n", err)
-		metrics.EndTime = time.Now()
-		return metrics
-	}
-	metrics.AddTime("csv_read", time.Since(readStart))
-
-	// Display appropriate message based on source
-	sourceType := "data source"
-	if config.DataSource == "snowflake" {
-		sourceType = "Snowflake"
-	} else if config.DataSource == "csv" {
-		sourceType = "CSV files"
-	}
-	fmt.Printf("📊 Loaded %d records from %s\n", len(records), sourceType)
+		if err := json.Unmarshal(bodyBytes, &fetchResp); err != nil {
+			return fmt.Errorf("failed to parse fetch response: %w", err)
+		}
 
-	// Calculate dynamic progress interval (report every 1%, but keep reasonable bounds)
-	// Minimum: 10,000 records, Maximum: 1,000,000 records
-	progressInterval := len(records) / 100 // 1% of total
-	if progressInterval < 10000 {
-		progressInterval = 10000
-	}
-	if progressInterval > 1000000 {
-		progressInterval = 1000000
-	}
-	config.ProgressInterval = progressInterval // Update config with calculated interval
-	fmt.Printf("📈 Progress updates every %d records (~%.1f%%)\n", progressInterval, float64(progressInterval)/float64(len(records))*100)
+		if len(fetchResp.Records) == 0 {
+			// No more records
+			break
+		}
 
-	// Split into batches
-	var batches [][]Record
-	for i := 0; i < len(records); i += config.BatchSize {
-		end := i + config.BatchSize
-		if end > len(records) {
-			end = len(records)
+		// Collect skyflow_ids
+		skyflowIDs := make([]string, 0, len(fetchResp.Records))
+		for _, record := range fetchResp.Records {
+			if record.Fields.SkyflowID != "" {
+				skyflowIDs = append(skyflowIDs, record.Fields.SkyflowID)
+			}
 		}
-		batches = append(batches, records[i:end])
-	}
 
-	fmt.Printf("🔥 Processing %d batches with %d concurrent workers\n", len(batches), config.MaxConcurrency)
+		if len(skyflowIDs) == 0 {
+			break
+		}
 
-	// Create shared HTTP client (connection pooling scaled to worker count)
-	client := createHTTPClient(config.MaxConcurrency)
+		// Delete in batches
+		for i := 0; i < len(skyflowIDs); i += batchSize {
+			end := i + batchSize
+			if end > len(skyflowIDs) {
+				end = len(skyflowIDs)
+			}
+			batch := skyflowIDs[i:end]
 
-	// Pre-construct API URL (avoid repeated string formatting in hot path)
-	apiURL := fmt.Sprintf("%s/v1/vaults/%s/%s", config.VaultURL, vaultConfig.ID, vaultConfig.Column)
+			deletePayload := map[string]interface{}{
+				"skyflow_ids": batch,
+			}
 
-	// Process batches concurrently with worker pool
-	var wg sync.WaitGroup
-	batchChan := make(chan struct {
-		num   int
-		batch []Record
-	}, config.MaxConcurrency*2) // Buffered channel for pipelining
+			payloadBytes, _ := json.Marshal(deletePayload)
+			req, err := http.NewRequest("DELETE", baseURL, bytes.NewReader(payloadBytes))
+			if err != nil {
+				return fmt.Errorf("failed to create delete request: %w", err)
+			}
 
-	// Start workers
-	for i := 0; i < config.MaxConcurrency; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for job := range batchChan {
-				// Track active worker
-				atomic.AddInt64(&metrics.ActiveWorkers, 1)
+			req.Header.Set("Authorization", "Bearer "+bearerToken)
+			req.Header.Set("Content-Type", "application/json")
 
-				err := sendBatch(client, config, vaultConfig, apiURL, job.batch, job.num, metrics)
+			resp, err := client.Do(req)
+			if err != nil {
+				return fmt.Errorf("failed to delete batch: %w", err)
+			}
 
-				atomic.AddInt64(&metrics.ActiveWorkers, -1)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
 
-				if err != nil {
-					// Log error with batch details
-					recordStart := job.num * len(job.batch)
-					recordEnd := recordStart + len(job.batch)
-					fmt.Printf("  ❌ Batch %d FAILED (records %d-%d): %v\n",
-						job.num, recordStart, recordEnd, err)
-				} else {
-					// Only count records on successful batch
-					for range job.batch {
-						metrics.AddRecord()
-					}
-				}
+			if resp.StatusCode != 200 {
+				return fmt.Errorf("failed to delete batch: status %d", resp.StatusCode)
+			}
 
-				// Progress reporting with HTTP status breakdown
-				totalRecords := atomic.LoadInt64(&metrics.TotalRecords)
-				successBatches := atomic.LoadInt64(&metrics.SuccessfulBatches)
-				failedBatches := atomic.LoadInt64(&metrics.FailedBatches)
-				rateLimited := atomic.LoadInt64(&metrics.RateLimited429)
-				immediate := atomic.LoadInt64(&metrics.ImmediateSuccesses)
-				retried := atomic.LoadInt64(&metrics.RetriedSuccesses)
+			totalDeleted += len(batch)
+		}
 
-				// Report every N successful records
-				if totalRecords > 0 && totalRecords%int64(config.ProgressInterval) == 0 {
-					elapsed := time.Since(metrics.StartTime).Seconds()
-					rate := float64(totalRecords) / elapsed
-					totalBatches := successBatches + failedBatches
-					successRate := float64(successBatches) / float64(totalBatches) * 100
+		fmt.Printf("  Iteration %d: Deleted %d records (total: %d)\n", iteration, len(skyflowIDs), totalDeleted)
+	}
 
-					fmt.Printf("  Progress: %d/%d records (%.1f%%) - %.0f records/sec | Batches: %d✅ (%d immediate, %d retried) %d❌ (%.0f%% success) | 429s: %d\n",
-						totalRecords, len(records),
-						float64(totalRecords)/float64(len(records))*100, rate,
-						successBatches, immediate, retried, failedBatches, successRate, rateLimited)
-				}
-			}
-		}()
+	if totalDeleted > 0 {
+		fmt.Printf("  ✅ %s vault cleared: %d records deleted\n", vaultConfig.Name, totalDeleted)
+	} else {
+		fmt.Printf("  ✅ %s vault confirmed empty\n", vaultConfig.Name)
 	}
 
-	// Start real-time metrics reporter
-	stopMetrics := make(chan struct{})
-	var metricsWg sync.WaitGroup
-	metricsWg.Add(1)
-	go func() {
-		defer metricsWg.Done()
-		ticker := time.NewTicker(3 * time.Second)
-		defer ticker.Stop()
+	return nil
+}
 
-		lastRequests := int64(0)
-		lastTime := time.Now()
+// Vault schema as returned by the Skyflow management API (GET /v1/vaults/{id})
+type VaultSchemaResponse struct {
+	Vault struct {
+		ID      string        `json:"ID"`
+		Name    string        `json:"name"`
+		Schemas []TableSchema `json:"schemas"`
+	} `json:"vault"`
+}
 
-		for {
-			select {
-			case <-stopMetrics:
-				return
-			case <-ticker.C:
-				now := time.Now()
-				currentRequests := atomic.LoadInt64(&metrics.TotalRequests)
-				deltaRequests := currentRequests - lastRequests
-				deltaTime := now.Sub(lastTime).Seconds()
-				requestRate := float64(deltaRequests) / deltaTime
+type TableSchema struct {
+	Name   string        `json:"name"`
+	Fields []FieldSchema `json:"fields"`
+}
 
-				activeWorkers := atomic.LoadInt64(&metrics.ActiveWorkers)
-				activeRequests := atomic.LoadInt64(&metrics.ActiveRequests)
-				rateLimited := atomic.LoadInt64(&metrics.RateLimited429)
-				totalRecords := atomic.LoadInt64(&metrics.TotalRecords)
+type FieldSchema struct {
+	Name       string `json:"name"`
+	Datatype   string `json:"datatype"`
+	Properties struct {
+		TokenTableConfig *struct {
+			TokenType struct {
+				Type string `json:"type"` // e.g. DETERMINISTIC_UUID, DETERMINISTIC_FPT, RANDOM_TOKEN
+			} `json:"tokenType"`
+		} `json:"tokenTableConfig"`
+	} `json:"properties"`
+}
 
-				elapsed := now.Sub(metrics.StartTime).Seconds()
-				recordRate := float64(totalRecords) / elapsed
+// PreflightCheck is a single pass/fail line in the pre-flight report
+type PreflightCheck struct {
+	Name   string
+	Passed bool
+	Detail string
+}
 
-				// Calculate average API latency
-				totalLatencyNanos := atomic.LoadInt64(&metrics.TotalAPILatency)
-				avgLatencyMs := 0.0
-				if currentRequests > 0 {
-					avgLatencyMs = float64(totalLatencyNanos) / float64(currentRequests) / 1_000_000
-				}
+var uuidTokenPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
-				// Get min/max latency
-				minLatencyNanos := atomic.LoadInt64(&metrics.MinAPILatency)
-				maxLatencyNanos := atomic.LoadInt64(&metrics.MaxAPILatency)
-				minLatencyMs := float64(minLatencyNanos) / 1_000_000
-				maxLatencyMs := float64(maxLatencyNanos) / 1_000_000
+// fetchVaultSchema retrieves a vault's table definitions from the management API
+func fetchVaultSchema(client *http.Client, config *Config, vaultID string) (*VaultSchemaResponse, int, error) {
+	bearerToken, err := config.Auth.Token()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to obtain bearer token: %w", err)
+	}
 
-				fmt.Printf("  [LIVE] Workers: %d/%d | HTTP: %d in-flight | Req: %.0f/s | Rec: %.0f/s | Latency: avg=%.0fms min=%.0fms max=%.0fms | 429s: %d\n",
-					activeWorkers, config.MaxConcurrency,
-					activeRequests,
-					requestRate,
-					recordRate,
-					avgLatencyMs,
-					minLatencyMs,
-					maxLatencyMs,
-					rateLimited)
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/vaults/%s", config.ManagementURL, vaultID), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create schema request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+bearerToken)
+	req.Header.Set("Accept", "application/json")
 
-				lastRequests = currentRequests
-				lastTime = now
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("schema request failed: %w", err)
+	}
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, resp.StatusCode, fmt.Errorf("schema request failed with status %d", resp.StatusCode)
+	}
+
+	var schema VaultSchemaResponse
+	if err := json.Unmarshal(bodyBytes, &schema); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to parse schema response: %w", err)
+	}
+	return &schema, resp.StatusCode, nil
+}
+
+// tokenMatchesFormat checks a source token against the column's token type.
+// UUID tokens must be UUIDs; format-preserving tokens must keep the value's length,
+// character classes and separators. Other token types are not checked.
+func tokenMatchesFormat(tokenType, value, token string) bool {
+	switch {
+	case strings.Contains(tokenType, "UUID"):
+		return uuidTokenPattern.MatchString(token)
+	case strings.Contains(tokenType, "FPT"):
+		if len(value) != len(token) {
+			return false
+		}
+		for i := 0; i < len(value); i++ {
+			v, t := value[i], token[i]
+			isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+			isLetter := func(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+			switch {
+			case isDigit(v):
+				if !isDigit(t) {
+					return false
+				}
+			case isLetter(v):
+				if !isLetter(t) {
+					return false
+				}
+			default:
+				if v != t {
+					return false // separators are preserved
+				}
 			}
 		}
-	}()
-
-	// Feed batches to workers
-	for batchNum, batch := range batches {
-		batchChan <- struct {
-			num   int
-			batch []Record
-		}{batchNum, batch}
+		return true
 	}
-	close(batchChan)
+	return true
+}
 
-	wg.Wait()
+// redactToken keeps enough of a token to find it again without exposing it
+func redactToken(token string) string {
+	if len(token) <= 8 {
+		return fmt.Sprintf("[REDACTED len=%d]", len(token))
+	}
+	return fmt.Sprintf("%s…%s (len=%d)", token[:4], token[len(token)-4:], len(token))
+}
 
-	// Stop metrics reporter
-	close(stopMetrics)
-	metricsWg.Wait()
+// sampleRecords picks sampleSize records uniformly at random (all records when sampleSize is 0
+// or larger than the source)
+func sampleRecords(records []Record, sampleSize int) []Record {
+	if sampleSize <= 0 || sampleSize >= len(records) {
+		return records
+	}
+	rng := randPool.Get().(*mathrand.Rand)
+	defer randPool.Put(rng)
 
-	metrics.EndTime = time.Now()
+	// Partial Fisher-Yates on a copy so the source slice keeps its order
+	sample := make([]Record, len(records))
+	copy(sample, records)
+	for i := 0; i < sampleSize; i++ {
+		j := i + rng.Intn(len(sample)-i)
+		sample[i], sample[j] = sample[j], sample[i]
+	}
+	return sample[:sampleSize]
+}
 
-	totalRecords := atomic.LoadInt64(&metrics.TotalRecords)
-	successBatches := atomic.LoadInt64(&metrics.SuccessfulBatches)
-	failedBatches := atomic.LoadInt64(&metrics.FailedBatches)
-	totalBatches := successBatches + failedBatches
+// Reservoir keeps a uniform random sample of a record stream (Algorithm R); size 0 keeps
+// every record
+type Reservoir struct {
+	Size    int
+	Seen    int
+	Records []Record
+	rng     *mathrand.Rand
+}
 
-	fmt.Printf("✅ %s processing complete: %d records uploaded | %d/%d batches successful (%.1f%%)\n",
-		vaultConfig.Name, totalRecords,
-		successBatches, totalBatches,
-		float64(successBatches)/float64(totalBatches)*100)
+func newReservoir(size int) *Reservoir {
+	return &Reservoir{Size: size, rng: randPool.Get().(*mathrand.Rand)}
+}
 
-	// Write error log if there were failures
-	if len(metrics.BatchErrors) > 0 {
-		if err := writeErrorLog(vaultConfig, metrics); err != nil {
-			fmt.Printf("  ⚠️  Failed to write error log: %v\n", err)
-		}
+// Add offers one record to the sample
+func (r *Reservoir) Add(record Record) {
+	r.Seen++
+	if r.Size <= 0 || len(r.Records) < r.Size {
+		r.Records = append(r.Records, record)
+		return
 	}
+	if j := r.rng.Intn(r.Seen); j < r.Size {
+		r.Records[j] = record
+	}
+}
 
-	return metrics
+// Release returns the random source to the pool
+func (r *Reservoir) Release() {
+	randPool.Put(r.rng)
 }
 
-// writeErrorLog creates a JSON file with details of failed batches
-func writeErrorLog(vaultConfig VaultConfig, metrics *Metrics) error {
-	if len(metrics.BatchErrors) == 0 {
-		return nil
+// sampleSource draws sampleSize records from the source, at the source when it supports
+// sampling; otherwise it reads everything and samples in memory. Returns the sample and the
+// number of records it was drawn from (-1 when unknown).
+func sampleSource(dataSource DataSource, vaultConfig VaultConfig, maxRecords, sampleSize int) ([]Record, int, error) {
+	if sampler, ok := dataSource.(RecordSampler); ok {
+		return sampler.SampleRecords(vaultConfig, maxRecords, sampleSize)
+	}
+	records, err := dataSource.ReadRecords(vaultConfig, maxRecords)
+	if err != nil {
+		return nil, 0, err
 	}
+	return sampleRecords(records, sampleSize), len(records), nil
+}
 
-	// Create filename with timestamp
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("error_log_%s_%s.json", vaultConfig.Name, timestamp)
+// preflightVault validates one vault's schema and a sample of its source tokens
+func preflightVault(client *http.Client, config *Config, vaultConfig VaultConfig, dataSource DataSource, sampleSize int) []PreflightCheck {
+	var checks []PreflightCheck
 
-	// Create error log structure
-	errorLog := struct {
-		VaultName     string       `json:"vault_name"`
-		VaultID       string       `json:"vault_id"`
-		Column        string       `json:"column"`
-		Timestamp     time.Time    `json:"timestamp"`
-		TotalErrors   int          `json:"total_errors"`
-		FailedRecords int          `json:"failed_records"`
-		Errors        []BatchError `json:"errors"`
-	}{
-		VaultName:     vaultConfig.Name,
-		VaultID:       vaultConfig.ID,
-		Column:        vaultConfig.Column,
-		Timestamp:     time.Now(),
-		TotalErrors:   len(metrics.BatchErrors),
-		FailedRecords: 0,
-		Errors:        metrics.BatchErrors,
+	// 1. Vault exists and is readable
+	schema, status, err := fetchVaultSchema(client, config, vaultConfig.ID)
+	if err != nil {
+		detail := err.Error()
+		switch status {
+		case 401, 403:
+			detail = fmt.Sprintf("not authorized to read vault %s (status %d) - check token/service account roles", vaultConfig.ID, status)
+		case 404:
+			detail = fmt.Sprintf("vault ID %s not found", vaultConfig.ID)
+		}
+		return append(checks, PreflightCheck{Name: "Vault reachable", Passed: false, Detail: detail})
 	}
-
-	// Count total failed records
-	for _, batchErr := range metrics.BatchErrors {
-		errorLog.FailedRecords += len(batchErr.Records)
+	checks = append(checks, PreflightCheck{Name: "Vault reachable", Passed: true,
+		Detail: fmt.Sprintf("%s (%s)", schema.Vault.Name, vaultConfig.ID)})
+
+	// 2. Table exists
+	var table *TableSchema
+	for i := range schema.Vault.Schemas {
+		if schema.Vault.Schemas[i].Name == vaultConfig.TableName() {
+			table = &schema.Vault.Schemas[i]
+			break
+		}
+	}
+	if table == nil {
+		return append(checks, PreflightCheck{Name: "Table exists", Passed: false,
+			Detail: fmt.Sprintf("table '%s' not found in vault", vaultConfig.TableName())})
+	}
+	checks = append(checks, PreflightCheck{Name: "Table exists", Passed: true, Detail: table.Name})
+
+	// 3. Every target column exists and is tokenized (BYOT needs a token table)
+	columns := []string{vaultConfig.Column}
+	if vaultConfig.IsMultiColumn() {
+		columns = columns[:0]
+		for _, mapping := range vaultConfig.Columns {
+			columns = append(columns, mapping.Column)
+		}
+	}
+	tokenTypes := make(map[string]string, len(columns))
+	columnsOK := true
+	for _, column := range columns {
+		var field *FieldSchema
+		for i := range table.Fields {
+			if table.Fields[i].Name == column {
+				field = &table.Fields[i]
+				break
+			}
+		}
+		switch {
+		case field == nil:
+			columnsOK = false
+			checks = append(checks, PreflightCheck{Name: "Column " + column, Passed: false,
+				Detail: fmt.Sprintf("column not found in table '%s'", table.Name)})
+		case field.Properties.TokenTableConfig == nil:
+			columnsOK = false
+			checks = append(checks, PreflightCheck{Name: "Column " + column, Passed: false,
+				Detail: "column is not tokenized - BYOT inserts will be rejected"})
+		default:
+			tokenType := field.Properties.TokenTableConfig.TokenType.Type
+			tokenTypes[column] = tokenType
+			checks = append(checks, PreflightCheck{Name: "Column " + column, Passed: true,
+				Detail: fmt.Sprintf("%s, token type %s", field.Datatype, tokenType)})
+		}
+	}
+	if !columnsOK {
+		return checks
 	}
 
-	// Write to file
-	file, err := os.Create(filename)
+	// 4. Sampled source tokens match each column's token format
+	if config.AppendSuffix {
+		return append(checks, PreflightCheck{Name: "Token format", Passed: true,
+			Detail: "skipped (append-suffix changes tokens before sending)"})
+	}
+	// Sample the whole set the load will read, so bad tokens later in the source are caught too,
+	// without holding it in memory
+	sample, sourceRecords, err := sampleSource(dataSource, vaultConfig, config.MaxRecords, sampleSize)
 	if err != nil {
-		return fmt.Errorf("failed to create error log file: %w", err)
+		return append(checks, PreflightCheck{Name: "Token format", Passed: false,
+			Detail: fmt.Sprintf("failed to read source records: %v", err)})
+	}
+	sampled := fmt.Sprintf("%d records sampled at the source", len(sample))
+	if sourceRecords >= 0 {
+		sampled = fmt.Sprintf("%d of %d records sampled", len(sample), sourceRecords)
 	}
-	defer file.Close()
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(errorLog); err != nil {
-		return fmt.Errorf("failed to write error log: %w", err)
+	checked := 0
+	var mismatches []string
+	mismatchCount := 0
+	checkPair := func(column, value, token string) {
+		checked++
+		if !tokenMatchesFormat(tokenTypes[column], value, token) {
+			mismatchCount++
+			if len(mismatches) < 5 {
+				mismatches = append(mismatches, fmt.Sprintf("%s:%s", column, redactToken(token)))
+			}
+		}
+	}
+	for _, record := range sample {
+		if len(record.Fields) > 0 {
+			for _, field := range record.Fields {
+				checkPair(field.Column, field.Value, field.Token)
+			}
+		} else {
+			checkPair(vaultConfig.Column, record.Value, record.Token)
+		}
 	}
 
-	fmt.Printf("  📋 Error log written to: %s (%d batches, %d records)\n",
-		filename, errorLog.TotalErrors, errorLog.FailedRecords)
+	if mismatchCount > 0 {
+		checks = append(checks, PreflightCheck{Name: "Token format", Passed: false,
+			Detail: fmt.Sprintf("%d/%d sampled tokens do not match the vault token format (e.g. %s; %s)",
+				mismatchCount, checked, strings.Join(mismatches, ", "), sampled)})
+	} else {
+		checks = append(checks, PreflightCheck{Name: "Token format", Passed: true,
+			Detail: fmt.Sprintf("%d sampled tokens match (%s)", checked, sampled)})
+	}
+	return checks
+}
 
-	return nil
+// runPreflight checks every vault before loading and prints a report; returns false if any check failed
+func runPreflight(config *Config, vaults []VaultConfig, dataSource DataSource, sampleSize int) bool {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
+	fmt.Printf("PRE-FLIGHT VALIDATION\n")
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+	fmt.Printf("Management API: %s | Token sample: %d random records per vault\n", config.ManagementURL, sampleSize)
+
+	clients := NewEndpointClients(0)
+	allPassed := true
+
+	for _, v := range vaults {
+		vc := config.ForVault(v)
+		fmt.Printf("\n%s (vault %s, table %s):\n", v.Name, v.ID, v.TableName())
+		for _, check := range preflightVault(clients.For(vc.VaultURL), vc, v, dataSource, sampleSize) {
+			status := "✅"
+			if !check.Passed {
+				status = "❌"
+				allPassed = false
+			}
+			fmt.Printf("  %s %-20s %s\n", status, check.Name, check.Detail)
+		}
+	}
+
+	if allPassed {
+		fmt.Printf("\n✅ Pre-flight passed for all vaults\n")
+	} else {
+		fmt.Printf("\n❌ Pre-flight failed - fix the issues above before loading\n")
+	}
+	return allPassed
 }
 
-// Display performance summary
-func displaySummary(allMetrics []*Metrics, totalStart time.Time) {
-	totalElapsed := time.Since(totalStart)
+const (
+	detokenizeBatchSize = 100  // Tokens per detokenize request (the API accepts up to 200)
+	verifyReportLimit   = 1000 // Max mismatching/missing tokens listed per vault in the report
+)
 
-	fmt.Printf("\n%s\n", strings.Repeat("=", 100))
-	fmt.Printf("COMPREHENSIVE PERFORMANCE SUMMARY\n")
-	fmt.Printf("%s\n", strings.Repeat("=", 100))
+// DetokenizeResponse is the vault's answer to POST /v1/vaults/{id}/detokenize
+type DetokenizeResponse struct {
+	Records []struct {
+		Token    string `json:"token"`
+		Value    string `json:"value"`
+		ValueStr string `json:"valueStr"`
+		Error    *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"records"`
+}
 
-	totalRecords := int64(0)
-	totalSuccessful := int64(0)
-	totalFailed := int64(0)
+// VerifyPair is one sampled source value/token pair to check against the vault
+type VerifyPair struct {
+	Column string
+	Value  string
+	Token  string
+}
 
-	for _, m := range allMetrics {
-		records := atomic.LoadInt64(&m.TotalRecords)
-		successful := atomic.LoadInt64(&m.SuccessfulBatches)
-		failed := atomic.LoadInt64(&m.FailedBatches)
+// VerifyFinding is a mismatching or missing token in the reconciliation report (never holds values)
+type VerifyFinding struct {
+	Column       string `json:"column"`
+	Token        string `json:"token"` // Redacted
+	SourceLength int    `json:"source_length"`
+	VaultLength  int    `json:"vault_length,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
 
-		if records > 0 || successful > 0 || failed > 0 {
-			fmt.Printf("\n%s VAULT PERFORMANCE:\n", m.VaultName)
-			fmt.Printf("  Records Uploaded:      %d (successfully processed)\n", records)
-			fmt.Printf("  Processing Time:       %.2f seconds\n", m.Duration().Seconds())
-			fmt.Printf("  Throughput:            %.0f records/sec (successful only)\n", m.Throughput())
-			totalBatches := successful + failed
-			if totalBatches > 0 {
-				fmt.Printf("  Batch Success Rate:    %d/%d batches (%.1f%%)\n",
-					successful, totalBatches,
-					float64(successful)/float64(totalBatches)*100)
+// VaultVerification holds the reconciliation results for one vault
+type VaultVerification struct {
+	Vault         string          `json:"vault"`
+	VaultID       string          `json:"vault_id"`
+	Table         string          `json:"table"`
+	VaultURL      string          `json:"vault_url,omitempty"` // Set when the vault overrides the run's endpoint
+	SourceRecords int             `json:"source_records"`
+	Checked       int             `json:"checked_tokens"`
+	Matched       int             `json:"matched"`
+	Mismatched    int             `json:"mismatched"`
+	Missing       int             `json:"missing"`
+	Errors        int             `json:"errors"` // Tokens not checked because the API call failed
+	Mismatches    []VerifyFinding `json:"mismatches"`
+	MissingTokens []VerifyFinding `json:"missing_tokens"`
+	Truncated     bool            `json:"truncated,omitempty"`
+	Duration      string          `json:"duration"`
+}
+
+// VerificationReport is written to the -verify-report file
+type VerificationReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	VaultURL    string               `json:"vault_url"`
+	Sample      int                  `json:"sample"` // Records sampled per vault (0 = all)
+	Passed      bool                 `json:"passed"`
+	Vaults      []*VaultVerification `json:"vaults"`
+}
+
+// detokenizeTokens resolves a batch of tokens, retrying 429/5xx with backoff and refreshing
+// the bearer token once on 401. Returns token -> value; tokens the vault doesn't know map to
+// an error message in the second map.
+func detokenizeTokens(client *http.Client, config *Config, vaultID string, tokens []string) (map[string]string, map[string]string, error) {
+	params := make([]map[string]string, len(tokens))
+	for i, token := range tokens {
+		params[i] = map[string]string{"token": token, "redaction": "PLAIN_TEXT"}
+	}
+	payload, err := json.Marshal(map[string]interface{}{"detokenizationParameters": params})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal detokenize request: %w", err)
+	}
+	url := fmt.Sprintf("%s/v1/vaults/%s/detokenize", config.VaultURL, vaultID)
+
+	maxRetries := 3
+	authRetried := false
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		bearerToken, err := config.Auth.Token()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to obtain bearer token: %w", err)
+		}
+
+		req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create detokenize request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if attempt < maxRetries-1 {
+				time.Sleep(time.Duration(1<<uint(attempt)) * time.Second)
+				continue
 			}
+			return nil, nil, fmt.Errorf("detokenize request failed: %w", err)
+		}
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
 
-			// API response summary
-			rateLimited := atomic.LoadInt64(&m.RateLimited429)
-			serverErrors := atomic.LoadInt64(&m.ServerErrors5xx)
-			immediate := atomic.LoadInt64(&m.ImmediateSuccesses)
-			retried := atomic.LoadInt64(&m.RetriedSuccesses)
+		if resp.StatusCode == 401 && !authRetried && config.Auth.CanRefresh() {
+			authRetried = true
+			config.Auth.Invalidate(bearerToken)
+			attempt--
+			continue
+		}
+		if (resp.StatusCode == 429 || resp.StatusCode >= 500) && attempt < maxRetries-1 {
+			time.Sleep(time.Duration(2<<uint(attempt)) * time.Second)
+			continue
+		}
+		if resp.StatusCode != 200 {
+			return nil, nil, fmt.Errorf("detokenize failed with status %d (body: %s)", resp.StatusCode, redactResponseBody(bodyBytes, nil))
+		}
 
-			if rateLimited > 0 || serverErrors > 0 || retried > 0 {
-				fmt.Printf("\n  API RESPONSE SUMMARY:\n")
-				if successful > 0 {
-					immediateRate := float64(immediate) / float64(successful) * 100
-					retriedRate := float64(retried) / float64(successful) * 100
-					fmt.Printf("    ✅ Immediate Successes: %d (%.1f%% of batches)\n", immediate, immediateRate)
-					fmt.Printf("    🔄 Retried Successes:   %d (%.1f%% of batches)\n", retried, retriedRate)
-				}
-				if rateLimited > 0 {
-					fmt.Printf("    ⚠️  Rate Limited (429):  %d responses during execution\n", rateLimited)
-				}
-				if serverErrors > 0 {
-					fmt.Printf("    ⚠️  Server Errors (5xx): %d responses during execution\n", serverErrors)
-				}
+		var detokenized DetokenizeResponse
+		if err := json.Unmarshal(bodyBytes, &detokenized); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse detokenize response: %w", err)
+		}
+
+		values := make(map[string]string, len(tokens))
+		missing := make(map[string]string)
+		for i, record := range detokenized.Records {
+			token := record.Token
+			if token == "" && i < len(tokens) {
+				token = tokens[i] // Records come back in request order
+			}
+			if record.Error != nil {
+				missing[token] = record.Error.Message
+				continue
+			}
+			value := record.Value
+			if value == "" {
+				value = record.ValueStr
+			}
+			values[token] = value
+		}
+		return values, missing, nil
+	}
+	return nil, nil, fmt.Errorf("max retries exceeded")
+}
+
+// verifyVault detokenizes a sample of source tokens and compares them to the source values
+func verifyVault(client *http.Client, config *Config, vaultConfig VaultConfig, dataSource DataSource, sampleSize int) (*VaultVerification, error) {
+	start := time.Now()
+	result := &VaultVerification{
+		Vault:         vaultConfig.Name,
+		VaultID:       vaultConfig.ID,
+		Table:         vaultConfig.TableName(),
+		Mismatches:    []VerifyFinding{},
+		MissingTokens: []VerifyFinding{},
+	}
+
+	records, err := dataSource.ReadRecords(vaultConfig, config.MaxRecords)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source records: %w", err)
+	}
+	// The vault holds transformed, validated values, so compare against the same
+	if _, err := applyVaultTransforms(vaultConfig, dataSource, records); err != nil {
+		return nil, err
+	}
+	var rows []int
+	if records, rows, _, err = applyVaultValidation(vaultConfig, dataSource, records, nil, config.Upsert); err != nil {
+		return nil, err
+	}
+	if records, _, err = config.Dedup.Filter(vaultConfig, dataSource, records, rows); err != nil {
+		return nil, err
+	}
+	result.SourceRecords = len(records)
+
+	sample := sampleRecords(records, sampleSize)
+	var pairs []VerifyPair
+	for _, record := range sample {
+		if len(record.Fields) > 0 {
+			for _, field := range record.Fields {
+				pairs = append(pairs, VerifyPair{Column: field.Column, Value: field.Value, Token: field.Token})
 			}
+		} else {
+			pairs = append(pairs, VerifyPair{Column: vaultConfig.Column, Value: record.Value, Token: record.Token})
+		}
+	}
+	if config.AppendSuffix {
+		// Reproducible suffixes: check what the load actually sent
+		for i := range pairs {
+			pairs[i].Value = config.Suffix.Apply(pairs[i].Value)
+			pairs[i].Token = config.Suffix.Apply(pairs[i].Token)
+		}
+	}
+	result.Checked = len(pairs)
+	fmt.Printf("  Sampled %s of %s source records (%s tokens)\n",
+		formatNumber(len(sample)), formatNumber(len(records)), formatNumber(len(pairs)))
 
-			// Detailed timing
-			csvRead := m.GetDuration("csv_read")
-			recordCreation := m.GetDuration("record_creation")
-			suffixGen := m.GetDuration("suffix_gen")
-			payloadCreation := m.GetDuration("payload_creation")
-			jsonSer := m.GetDuration("json_serialization")
-			baseDelay := m.GetDuration("base_delay")
-			apiCall := m.GetDuration("api_call")
-			retryDelay := m.GetDuration("retry_delay")
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, max(1, config.MaxConcurrency))
 
-			cumulative := csvRead + recordCreation + suffixGen + payloadCreation +
-				jsonSer + baseDelay + apiCall + retryDelay
+	addFinding := func(list *[]VerifyFinding, finding VerifyFinding) {
+		if len(*list) < verifyReportLimit {
+			*list = append(*list, finding)
+		} else {
+			result.Truncated = true
+		}
+	}
 
-			avgConcurrency := cumulative.Seconds() / m.Duration().Seconds()
+	for batchStart := 0; batchStart < len(pairs); batchStart += detokenizeBatchSize {
+		batch := pairs[batchStart:min(batchStart+detokenizeBatchSize, len(pairs))]
 
-			fmt.Printf("\n  DETAILED TIMING BREAKDOWN (Cumulative across all parallel workers):\n")
-			fmt.Printf("    %-25s %12s %10s %16s\n", "Component", "Cumulative", "% of Total", "Est. Wall Clock")
-			fmt.Printf("    %s %s %s %s\n", strings.Repeat("-", 25), strings.Repeat("-", 12), strings.Repeat("-", 10), strings.Repeat("-", 16))
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(batch []VerifyPair) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
 
-			printTiming := func(label string, dur time.Duration, warn bool) {
-				pct := 0.0
-				if cumulative.Seconds() > 0 {
-					pct = dur.Seconds() / cumulative.Seconds() * 100
-				}
-				estWall := 0.0
-				if avgConcurrency > 0 {
-					estWall = dur.Seconds() / avgConcurrency
+			tokens := make([]string, len(batch))
+			for i, pair := range batch {
+				tokens[i] = pair.Token
+			}
+			values, missing, err := detokenizeTokens(client, config, vaultConfig.ID, tokens)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors += len(batch)
+				fmt.Printf("  ❌ Detokenize batch failed: %v\n", err)
+				return
+			}
+			for _, pair := range batch {
+				value, found := values[pair.Token]
+				switch {
+				case !found:
+					result.Missing++
+					reason := missing[pair.Token]
+					if reason == "" {
+						reason = "token not returned by vault"
+					}
+					addFinding(&result.MissingTokens, VerifyFinding{Column: pair.Column, Token: redactToken(pair.Token),
+						SourceLength: len(pair.Value), Error: reason})
+				case value == pair.Value:
+					result.Matched++
+				default:
+					result.Mismatched++
+					addFinding(&result.Mismatches, VerifyFinding{Column: pair.Column, Token: redactToken(pair.Token),
+						SourceLength: len(pair.Value), VaultLength: len(value)})
 				}
-				fmt.Printf("    %-25s %10.2fs %9.1f%% %14.2fs\n", label, dur.Seconds(), pct, estWall)
 			}
+		}(batch)
+	}
+	wg.Wait()
 
-			printTiming("Data Source Read", csvRead, false)
-			printTiming("Record Creation", recordCreation, false)
-			printTiming("Suffix Generation", suffixGen, false)
-			printTiming("Payload Creation", payloadCreation, false)
-			printTiming("JSON Serialization", jsonSer, false)
-			printTiming("BASE_REQUEST_DELAY", baseDelay, false)
-			printTiming("Skyflow API Calls", apiCall, false)
-			printTiming("Retry Delays", retryDelay, false)
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	return result, nil
+}
 
-			fmt.Printf("    %s %s %s %s\n", strings.Repeat("-", 25), strings.Repeat("-", 12), strings.Repeat("-", 10), strings.Repeat("-", 16))
-			fmt.Printf("    %-25s %10.2fs %9s %14.2fs (actual)\n", "TOTAL (Cumulative)", cumulative.Seconds(), "100.0%", m.Duration().Seconds())
-			fmt.Printf("\n    Average Concurrency: %.1fx (concurrent workers executing simultaneously)\n", avgConcurrency)
+// runVerification checks every vault, prints the reconciliation summary and writes the JSON
+// report; returns false if any token was mismatched, missing or could not be checked
+func runVerification(config *Config, vaults []VaultConfig, dataSource DataSource, sampleSize int, reportPath string) bool {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
+	fmt.Printf("POST-LOAD VERIFICATION\n")
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+	sampleLabel := "all records"
+	if sampleSize > 0 {
+		sampleLabel = fmt.Sprintf("random sample of %s records per vault", formatNumber(sampleSize))
+	}
+	fmt.Printf("Vault URL: %s | Sample: %s\n", config.VaultURL, sampleLabel)
+
+	clients := NewEndpointClients(config.MaxConcurrency)
+	report := VerificationReport{
+		GeneratedAt: time.Now(),
+		VaultURL:    config.VaultURL,
+		Sample:      sampleSize,
+		Passed:      true,
+	}
 
-			totalRecords += records
-			totalSuccessful += successful
-			totalFailed += failed
+	for _, v := range vaults {
+		vc := config.ForVault(v)
+		fmt.Printf("\n%s (vault %s, table %s%s):\n", v.Name, v.ID, v.TableName(), endpointLabel(config, vc))
+		result, err := verifyVault(clients.For(vc.VaultURL), vc, v, dataSource, sampleSize)
+		if err != nil {
+			fmt.Printf("  ❌ %v\n", err)
+			report.Passed = false
+			continue
+		}
+		if vc.VaultURL != config.VaultURL {
+			result.VaultURL = vc.VaultURL
+		}
+		report.Vaults = append(report.Vaults, result)
+
+		status := "✅"
+		if result.Mismatched > 0 || result.Missing > 0 || result.Errors > 0 {
+			status = "❌"
+			report.Passed = false
+		}
+		fmt.Printf("  %s Matched: %s | Mismatched: %s | Missing: %s | Errors: %s (%s)\n", status,
+			formatNumber(result.Matched), formatNumber(result.Mismatched), formatNumber(result.Missing),
+			formatNumber(result.Errors), result.Duration)
+		for i, finding := range result.Mismatches {
+			if i == 5 {
+				fmt.Printf("     ... %d more in the report\n", result.Mismatched-5)
+				break
+			}
+			fmt.Printf("     ≠ %s %s (source len %d, vault len %d)\n", finding.Column, finding.Token, finding.SourceLength, finding.VaultLength)
 		}
 	}
 
-	// Overall summary
-	fmt.Printf("\n%s\n", strings.Repeat("=", 50))
-	fmt.Printf("OVERALL PERFORMANCE SUMMARY\n")
-	fmt.Printf("%s\n", strings.Repeat("=", 50))
-	fmt.Printf("Total Records Processed:   %d\n", totalRecords)
-	fmt.Printf("Total Elapsed Time (Wall Clock): %.2f seconds\n", totalElapsed.Seconds())
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Printf("\n❌ Failed to encode verification report: %v\n", err)
+		return false
+	}
+	if err := os.WriteFile(reportPath, data, 0600); err != nil {
+		fmt.Printf("\n❌ Failed to write verification report: %v\n", err)
+		return false
+	}
+	fmt.Printf("\n📋 Reconciliation report: %s\n", reportPath)
 
-	// Error summary
-	totalBatches := totalSuccessful + totalFailed
-	if totalFailed == 0 {
-		fmt.Printf("\nERROR SUMMARY:\n")
-		fmt.Printf("  ✅ No errors encountered\n")
-		fmt.Printf("  Total Batches:           %d (all successful)\n", totalBatches)
+	if report.Passed {
+		fmt.Printf("✅ Verification passed - every sampled token maps to its source value\n")
 	} else {
-		successRate := float64(totalSuccessful) / float64(totalBatches) * 100
-		fmt.Printf("\nERROR SUMMARY:\n")
-		fmt.Printf("  Errors detected:\n")
-		fmt.Printf("  Total Batches:           %d\n", totalBatches)
-		fmt.Printf("  Successful Batches:      %d (%.1f%%)\n", totalSuccessful, successRate)
-		fmt.Printf("  Failed Batches:          %d (%.1f%%)\n", totalFailed, 100-successRate)
+		fmt.Printf("❌ Verification failed - see mismatched/missing tokens above\n")
 	}
+	return report.Passed
+}
 
-	fmt.Printf("\nTHROUGHPUT RATES:\n")
-	fmt.Printf("  End-to-End Throughput:      %.0f records/sec (actual)\n",
-		float64(totalRecords)/totalElapsed.Seconds())
+const reconcilePageSize = 100 // Records per list API page when counting vault records
 
-	// Error log summary
-	hasErrors := false
-	for _, m := range allMetrics {
-		if len(m.BatchErrors) > 0 {
-			hasErrors = true
-			break
+// SourceCounts summarizes the value/token pairs a data source holds for one vault
+type SourceCounts struct {
+	Rows           int `json:"rows"`
+	DistinctPairs  int `json:"distinct_pairs"`       // Distinct value/token pairs (whole row for multi-column vaults)
+	DistinctKeys   int `json:"distinct_upsert_keys"` // Distinct values of the upsert column
+	DuplicateRows  int `json:"duplicate_rows"`       // Rows repeating an earlier pair
+	ValueConflicts int `json:"value_conflicts"`      // Values that appear with more than one token
+	TokenConflicts int `json:"token_conflicts"`      // Tokens that appear with more than one value
+}
+
+// VaultReconciliation is one row of the reconcile diff table
+type VaultReconciliation struct {
+	Vault        string       `json:"vault"`
+	VaultID      string       `json:"vault_id"`
+	Table        string       `json:"table"`
+	VaultURL     string       `json:"vault_url,omitempty"` // Set when the vault overrides the run's endpoint
+	Source       SourceCounts `json:"source"`
+	Expected     int          `json:"expected_vault_records"`
+	VaultRecords int          `json:"vault_records"`
+	Diff         int          `json:"diff"` // vault_records - expected
+	Error        string       `json:"error,omitempty"`
+}
+
+// ReconciliationReport is written to the -reconcile-report file
+type ReconciliationReport struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	VaultURL    string                 `json:"vault_url"`
+	Upsert      bool                   `json:"upsert"`
+	Passed      bool                   `json:"passed"`
+	Vaults      []*VaultReconciliation `json:"vaults"`
+}
+
+// hashKey reduces a composite key to a SHA-256 digest so distinct counting of large
+// sources stays compact; a collision would silently merge two records, so the hash
+// must be collision resistant rather than merely fast
+func hashKey(parts ...string) [32]byte {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	var key [32]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// countSourcePairs counts rows, distinct pairs, distinct upsert keys and token/value conflicts
+func countSourcePairs(records []Record, vaultConfig VaultConfig) SourceCounts {
+	counts := SourceCounts{Rows: len(records)}
+
+	pairs := make(map[[32]byte]struct{}, len(records))
+	keys := make(map[[32]byte]struct{}, len(records))
+	valueTokens := make(map[[32]byte][32]byte) // column+value -> token
+	tokenValues := make(map[[32]byte][32]byte) // column+token -> value
+	valueConflicts := make(map[[32]byte]struct{})
+	tokenConflicts := make(map[[32]byte]struct{})
+
+	checkPair := func(column, value, token string) {
+		valueKey, tokenKey := hashKey(column, value), hashKey(column, token)
+		tokenHash, valueHash := hashKey(token), hashKey(value)
+		if seen, ok := valueTokens[valueKey]; ok && seen != tokenHash {
+			valueConflicts[valueKey] = struct{}{}
+		} else if !ok {
+			valueTokens[valueKey] = tokenHash
+		}
+		if seen, ok := tokenValues[tokenKey]; ok && seen != valueHash {
+			tokenConflicts[tokenKey] = struct{}{}
+		} else if !ok {
+			tokenValues[tokenKey] = valueHash
 		}
 	}
 
-	if hasErrors {
-		fmt.Printf("\n📋 ERROR LOGS CREATED:\n")
-		for _, m := range allMetrics {
-			if len(m.BatchErrors) > 0 {
-				totalFailedRecords := 0
-				for _, batchErr := range m.BatchErrors {
-					totalFailedRecords += len(batchErr.Records)
+	var parts []string
+	for _, record := range records {
+		upsertKey := record.Value
+		if len(record.Fields) > 0 {
+			parts = parts[:0]
+			upsertKey = ""
+			for _, field := range record.Fields {
+				parts = append(parts, field.Column, field.Value, field.Token)
+				checkPair(field.Column, field.Value, field.Token)
+				if field.Column == vaultConfig.Column {
+					upsertKey = field.Value
 				}
-				fmt.Printf("  • %s: error_log_%s_*.json (%d batches, %d records)\n",
-					m.VaultName, m.VaultName, len(m.BatchErrors), totalFailedRecords)
 			}
+		} else {
+			parts = append(parts[:0], vaultConfig.Column, record.Value, record.Token)
+			checkPair(vaultConfig.Column, record.Value, record.Token)
 		}
-		fmt.Printf("\n  ⚠️  Review error logs and re-run failed records if needed\n")
+
+		pairKey := hashKey(parts...)
+		if _, seen := pairs[pairKey]; seen {
+			counts.DuplicateRows++
+		} else {
+			pairs[pairKey] = struct{}{}
+		}
+		keys[hashKey(upsertKey)] = struct{}{}
 	}
 
-	fmt.Printf("\n🎉 All vaults processed!\n")
+	counts.DistinctPairs = len(pairs)
+	counts.DistinctKeys = len(keys)
+	counts.ValueConflicts = len(valueConflicts)
+	counts.TokenConflicts = len(tokenConflicts)
+	return counts
 }
 
-// Clear vault table - delete all records
-func clearVaultTable(client *http.Client, config *Config, vaultConfig VaultConfig) error {
-	fmt.Printf("\n🗑️  Clearing %s vault...\n", vaultConfig.Name)
-
-	baseURL := fmt.Sprintf("%s/v1/vaults/%s/%s", config.VaultURL, vaultConfig.ID, vaultConfig.Column)
-	totalDeleted := 0
-	iteration := 0
-	fetchLimit := 100
-	batchSize := 100
+// fetchVaultPage lists one page of skyflow_ids and returns how many records it held
+func fetchVaultPage(client *http.Client, config *Config, baseURL string, offset int) (int, error) {
+	// Values are not needed to count, so ask for them redacted
+	fetchURL := fmt.Sprintf("%s?offset=%d&limit=%d&redaction=REDACTED", baseURL, offset, reconcilePageSize)
 
-	for {
-		iteration++
+	maxRetries := 3
+	authRetried := false
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		bearerToken, err := config.Auth.Token()
+		if err != nil {
+			return 0, fmt.Errorf("failed to obtain bearer token: %w", err)
+		}
 
-		// Fetch records to delete
-		fetchURL := fmt.Sprintf("%s?offset=0&limit=%d", baseURL, fetchLimit)
 		req, err := http.NewRequest("GET", fetchURL, nil)
 		if err != nil {
-			return fmt.Errorf("failed to create fetch request: %w", err)
+			return 0, fmt.Errorf("failed to create list request: %w", err)
 		}
-		req.Header.Set("Authorization", "Bearer "+config.BearerToken)
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+		req.Header.Set("Accept", "application/json")
 
 		resp, err := client.Do(req)
 		if err != nil {
-			return fmt.Errorf("failed to fetch records: %w", err)
+			if attempt < maxRetries-1 {
+				time.Sleep(time.Duration(1<<uint(attempt)) * time.Second)
+				continue
+			}
+			return 0, fmt.Errorf("list request failed: %w", err)
 		}
-
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 
+		if resp.StatusCode == 401 && !authRetried && config.Auth.CanRefresh() {
+			authRetried = true
+			config.Auth.Invalidate(bearerToken)
+			attempt--
+			continue
+		}
+		if (resp.StatusCode == 429 || resp.StatusCode >= 500) && attempt < maxRetries-1 {
+			time.Sleep(time.Duration(2<<uint(attempt)) * time.Second)
+			continue
+		}
 		if resp.StatusCode == 404 {
-			// Table is empty
-			if iteration == 1 {
-				fmt.Printf("  ℹ️  %s vault is already empty\n", vaultConfig.Name)
-			}
-			break
+			return 0, nil // Table is empty
 		}
-
 		if resp.StatusCode != 200 {
-			return fmt.Errorf("failed to fetch records: status %d", resp.StatusCode)
+			return 0, fmt.Errorf("list request failed with status %d (body: %s)", resp.StatusCode, redactResponseBody(bodyBytes, nil))
 		}
 
-		// Parse response to get skyflow_ids
 		var fetchResp struct {
-			Records []struct {
-				Fields struct {
-					SkyflowID string `json:"skyflow_id"`
-				} `json:"fields"`
-			} `json:"records"`
+			Records []json.RawMessage `json:"records"`
 		}
-
 		if err := json.Unmarshal(bodyBytes, &fetchResp); err != nil {
-			return fmt.Errorf("failed to parse fetch response: %w", err)
+			return 0, fmt.Errorf("failed to parse list response: %w", err)
 		}
+		return len(fetchResp.Records), nil
+	}
+	return 0, fmt.Errorf("max retries exceeded")
+}
 
-		if len(fetchResp.Records) == 0 {
-			// No more records
-			break
+// countVaultRecords pages through the table with the list API, fetching up to
+// MaxConcurrency pages at a time until a short page marks the end
+func countVaultRecords(client *http.Client, config *Config, vaultConfig VaultConfig) (int, error) {
+	baseURL := fmt.Sprintf("%s/v1/vaults/%s/%s", config.VaultURL, vaultConfig.ID, vaultConfig.TableName())
+	workers := max(1, config.MaxConcurrency)
+
+	total := 0
+	for window := 0; ; window += workers {
+		pageCounts := make([]int, workers)
+		errs := make([]error, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				pageCounts[i], errs[i] = fetchVaultPage(client, config, baseURL, (window+i)*reconcilePageSize)
+			}(i)
 		}
+		wg.Wait()
 
-		// Collect skyflow_ids
-		skyflowIDs := make([]string, 0, len(fetchResp.Records))
-		for _, record := range fetchResp.Records {
-			if record.Fields.SkyflowID != "" {
-				skyflowIDs = append(skyflowIDs, record.Fields.SkyflowID)
+		for i := 0; i < workers; i++ {
+			if errs[i] != nil {
+				return total, errs[i]
+			}
+			total += pageCounts[i]
+			if pageCounts[i] < reconcilePageSize {
+				return total, nil
 			}
 		}
-
-		if len(skyflowIDs) == 0 {
-			break
+		if (window/workers)%10 == 9 {
+			fmt.Printf("  ... %s vault records counted\n", formatNumber(total))
 		}
+	}
+}
 
-		// Delete in batches
-		for i := 0; i < len(skyflowIDs); i += batchSize {
-			end := i + batchSize
-			if end > len(skyflowIDs) {
-				end = len(skyflowIDs)
-			}
-			batch := skyflowIDs[i:end]
+// runReconciliation compares source pair counts with vault record counts, prints the diff
+// table and writes the JSON report; returns false if any vault differs
+func runReconciliation(config *Config, vaults []VaultConfig, dataSource DataSource, reportPath string) bool {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
+	fmt.Printf("SOURCE VS VAULT RECONCILIATION\n")
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+	// Random suffixes make every row's value unique, so upserts never collapse them; deterministic
+	// and run-tag suffixes give equal values equal suffixes, so duplicates still collapse
+	randomSuffix := config.AppendSuffix && config.Suffix.Strategy == "random"
+	switch {
+	case config.Upsert && randomSuffix:
+		fmt.Printf("Mode: upsert with random suffixes - expected vault records = source rows (every suffixed value is unique)\n")
+	case config.Upsert:
+		fmt.Printf("Mode: upsert - expected vault records = distinct values of the upsert column\n")
+	default:
+		fmt.Printf("Mode: insert - expected vault records = source rows (duplicate rows become duplicate records)\n")
+	}
 
-			deletePayload := map[string]interface{}{
-				"skyflow_ids": batch,
-			}
+	clients := NewEndpointClients(config.MaxConcurrency)
+	report := ReconciliationReport{
+		GeneratedAt: time.Now(),
+		VaultURL:    config.VaultURL,
+		Upsert:      config.Upsert,
+		Passed:      true,
+	}
 
-			payloadBytes, _ := json.Marshal(deletePayload)
-			req, err := http.NewRequest("DELETE", baseURL, bytes.NewReader(payloadBytes))
-			if err != nil {
-				return fmt.Errorf("failed to create delete request: %w", err)
-			}
+	for _, v := range vaults {
+		vc := config.ForVault(v)
+		client := clients.For(vc.VaultURL)
+		result := &VaultReconciliation{Vault: v.Name, VaultID: v.ID, Table: v.TableName()}
+		if vc.VaultURL != config.VaultURL {
+			result.VaultURL = vc.VaultURL
+		}
+		report.Vaults = append(report.Vaults, result)
+		fmt.Printf("\n%s (vault %s, table %s%s):\n", v.Name, v.ID, v.TableName(), endpointLabel(config, vc))
 
-			req.Header.Set("Authorization", "Bearer "+config.BearerToken)
-			req.Header.Set("Content-Type", "application/json")
+		records, err := dataSource.ReadRecords(v, config.MaxRecords)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to read source records: %v", err)
+			fmt.Printf("  ❌ %s\n", result.Error)
+			report.Passed = false
+			continue
+		}
+		if _, err := applyVaultTransforms(v, dataSource, records); err != nil {
+			result.Error = fmt.Sprintf("invalid transforms: %v", err)
+			fmt.Printf("  ❌ %s\n", result.Error)
+			report.Passed = false
+			continue
+		}
+		var rows []int
+		if records, rows, _, err = applyVaultValidation(v, dataSource, records, nil, config.Upsert); err != nil {
+			result.Error = fmt.Sprintf("invalid validation rules: %v", err)
+			fmt.Printf("  ❌ %s\n", result.Error)
+			report.Passed = false
+			continue
+		}
+		if records, _, err = config.Dedup.Filter(v, dataSource, records, rows); err != nil {
+			result.Error = err.Error()
+			fmt.Printf("  ❌ %s\n", result.Error)
+			report.Passed = false
+			continue
+		}
+		result.Source = countSourcePairs(records, v)
 
-			resp, err := client.Do(req)
-			if err != nil {
-				return fmt.Errorf("failed to delete batch: %w", err)
-			}
+		result.Expected = result.Source.Rows
+		if config.Upsert && !randomSuffix {
+			result.Expected = result.Source.DistinctKeys
+		}
 
-			io.Copy(io.Discard, resp.Body)
-			resp.Body.Close()
+		vaultCount, err := countVaultRecords(client, vc, v)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to count vault records: %v", err)
+			fmt.Printf("  ❌ %s\n", result.Error)
+			report.Passed = false
+			continue
+		}
+		result.VaultRecords = vaultCount
+		result.Diff = vaultCount - result.Expected
+		if result.Diff != 0 {
+			report.Passed = false
+		}
+		fmt.Printf("  Source rows: %s | Distinct pairs: %s | Vault records: %s\n",
+			formatNumber(result.Source.Rows), formatNumber(result.Source.DistinctPairs), formatNumber(vaultCount))
+	}
 
-			if resp.StatusCode != 200 {
-				return fmt.Errorf("failed to delete batch: status %d", resp.StatusCode)
-			}
+	// Diff table
+	fmt.Printf("\n  %-12s %14s %14s %14s %14s %12s  %s\n", "Vault", "Source Rows", "Distinct", "Expected", "Vault", "Diff", "Status")
+	fmt.Printf("  %s %s %s %s %s %s  %s\n", strings.Repeat("-", 12), strings.Repeat("-", 14), strings.Repeat("-", 14),
+		strings.Repeat("-", 14), strings.Repeat("-", 14), strings.Repeat("-", 12), strings.Repeat("-", 6))
+	for _, r := range report.Vaults {
+		status, diff := "✅", "0"
+		if r.Diff != 0 {
+			diff = fmt.Sprintf("%+d", r.Diff)
+		}
+		switch {
+		case r.Error != "":
+			status, diff = "❌ error", "-"
+		case r.Diff > 0:
+			status = "❌ extra"
+		case r.Diff < 0:
+			status = "❌ missing"
+		}
+		fmt.Printf("  %-12s %14s %14s %14s %14s %12s  %s\n", r.Vault, formatNumber(r.Source.Rows),
+			formatNumber(r.Source.DistinctPairs), formatNumber(r.Expected), formatNumber(r.VaultRecords), diff, status)
+	}
 
-			totalDeleted += len(batch)
+	// Duplicate/conflict notes explain most diffs
+	for _, r := range report.Vaults {
+		if r.Source.DuplicateRows > 0 || r.Source.ValueConflicts > 0 || r.Source.TokenConflicts > 0 {
+			fmt.Printf("\n  ⚠️  %s source: %s duplicate rows, %s values with several tokens, %s tokens with several values\n",
+				r.Vault, formatNumber(r.Source.DuplicateRows), formatNumber(r.Source.ValueConflicts), formatNumber(r.Source.TokenConflicts))
 		}
+	}
 
-		fmt.Printf("  Iteration %d: Deleted %d records (total: %d)\n", iteration, len(skyflowIDs), totalDeleted)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Printf("\n❌ Failed to encode reconciliation report: %v\n", err)
+		return false
 	}
+	if err := os.WriteFile(reportPath, data, 0600); err != nil {
+		fmt.Printf("\n❌ Failed to write reconciliation report: %v\n", err)
+		return false
+	}
+	fmt.Printf("\n📋 Reconciliation report: %s\n", reportPath)
 
-	if totalDeleted > 0 {
-		fmt.Printf("  ✅ %s vault cleared: %d records deleted\n", vaultConfig.Name, totalDeleted)
+	if report.Passed {
+		fmt.Printf("✅ Reconciliation passed - vault record counts match the source\n")
 	} else {
-		fmt.Printf("  ✅ %s vault confirmed empty\n", vaultConfig.Name)
+		fmt.Printf("❌ Reconciliation failed - vault record counts differ from the source\n")
+	}
+	return report.Passed
+}
+
+// validateVaultConfigs checks table/column settings before any data is read
+func validateVaultConfigs(vaults []VaultConfig, upsert bool) error {
+	for _, v := range vaults {
+		if _, err := compileVaultTransforms(v); err != nil {
+			return fmt.Errorf("vault %s: %w", v.Name, err)
+		}
+		if _, err := compileVaultValidation(v, upsert); err != nil {
+			return fmt.Errorf("vault %s: %w", v.Name, err)
+		}
+		if v.VaultURL != "" {
+			if u, err := url.Parse(v.VaultURL); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("vault %s: invalid vault_url %q", v.Name, v.VaultURL)
+			}
+		}
+		if p := v.Performance; p != nil {
+			if p.BatchSize < 0 || p.MaxConcurrency < 0 || (p.BaseDelayMs != nil && *p.BaseDelayMs < 0) {
+				return fmt.Errorf("vault %s: performance overrides must not be negative", v.Name)
+			}
+		}
+		if v.IsMultiColumn() {
+			if v.Table == "" {
+				return fmt.Errorf("vault %s: multi-column vaults require a table name", v.Name)
+			}
+			seen := make(map[string]bool, len(v.Columns))
+			for _, mapping := range v.Columns {
+				if mapping.Source == "" || mapping.Column == "" {
+					return fmt.Errorf("vault %s: every column mapping needs a source and a column", v.Name)
+				}
+				if seen[mapping.Column] {
+					return fmt.Errorf("vault %s: column %s is mapped more than once", v.Name, mapping.Column)
+				}
+				seen[mapping.Column] = true
+			}
+			if upsert && v.Column == "" {
+				return fmt.Errorf("vault %s: upsert on a multi-column vault requires column (the upsert key)", v.Name)
+			}
+			if upsert && !seen[v.Column] {
+				return fmt.Errorf("vault %s: upsert key column %s is not one of the mapped columns", v.Name, v.Column)
+			}
+		} else if v.Column == "" {
+			return fmt.Errorf("vault %s: column is required", v.Name)
+		}
 	}
-
 	return nil
 }
 
@@ -1659,9 +7524,9 @@ func loadConfigFile(filepath string) (*FileConfig, error) {
 
 // promptForPassword prompts the user for a password securely (without echo)
 func promptForPassword(prompt string) (string, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(terminal, prompt)
 	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println() // Print newline after password input
+	fmt.Fprintln(terminal) // Print newline after password input
 	if err != nil {
 		return "", err
 	}
@@ -1670,7 +7535,7 @@ func promptForPassword(prompt string) (string, error) {
 
 // promptForInput prompts the user for text input
 func promptForInput(prompt string) (string, error) {
-	fmt.Print(prompt)
+	fmt.Fprint(terminal, prompt)
 	reader := bufio.NewReader(os.Stdin)
 	input, err := reader.ReadString('\n')
 	if err != nil {
@@ -1688,8 +7553,8 @@ func displayErrorLogStats(errorLogSource *ErrorLogDataSource, config *Config) (b
 	// Basic metadata
 	fmt.Printf("\n📋 ERROR LOG INFORMATION:\n")
 	fmt.Printf("   File: %s\n", errorLogSource.ErrorLogPath)
-	fmt.Printf("   Vault: %s (ID: %s, Column: %s)\n",
-		errorLogSource.VaultName, errorLogSource.VaultID, errorLogSource.Column)
+	fmt.Printf("   Vault: %s (ID: %s, Table: %s, Column: %s)\n",
+		errorLogSource.VaultName, errorLogSource.VaultID, errorLogSource.Table, errorLogSource.Column)
 	fmt.Printf("   Original Error Timestamp: %s\n", errorLogSource.ErrorLog.Timestamp.Format("2006-01-02 15:04:05"))
 	fmt.Printf("   Time Since Error: %s ago\n", time.Since(errorLogSource.ErrorLog.Timestamp).Round(time.Second))
 
@@ -1756,89 +7621,927 @@ func displayErrorLogStats(errorLogSource *ErrorLogDataSource, config *Config) (b
 
 	// Estimated runtime
 	fmt.Printf("\n⏱️  ESTIMATED RUNTIME:\n")
-	// Assume ~200ms per batch average (including retries)
-	avgBatchTime := 0.2 // seconds
-	estimatedSeconds := float64(numBatches) * avgBatchTime / float64(config.MaxConcurrency)
-	if estimatedSeconds < 60 {
-		fmt.Printf("   Estimated Time: ~%.0f seconds\n", estimatedSeconds)
-	} else if estimatedSeconds < 3600 {
-		fmt.Printf("   Estimated Time: ~%.1f minutes\n", estimatedSeconds/60)
+	fmt.Printf("   Estimated Time: %s\n", formatEstimate(estimateRuntime(numBatches, config)))
+	fmt.Printf("   (Actual time may vary based on API response times and rate limits)\n")
+
+	// Confirmation prompt
+	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
+	fmt.Printf("⚠️  WARNING: This will attempt to reprocess %s records.\n", formatNumber(len(errorLogSource.Records)))
+	if !config.Upsert {
+		fmt.Printf("⚠️  Upsert is DISABLED - records may fail if they already exist.\n")
+		fmt.Printf("    Consider adding the -upsert flag if you want to update existing records.\n")
+	}
+	fmt.Printf("%s\n\n", strings.Repeat("=", 80))
+
+	// Prompt for confirmation
+	response, err := promptForInput("Do you want to proceed? (yes/no): ")
+	if err != nil {
+		return false, err
+	}
+
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "yes" || response == "y", nil
+}
+
+// formatNumber formats an integer with comma separators
+func formatNumber(n int) string {
+	s := strconv.Itoa(n)
+	if len(s) <= 3 {
+		return s
+	}
+
+	// Add commas from right to left
+	var result []byte
+	for i, digit := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			result = append(result, ',')
+		}
+		result = append(result, byte(digit))
+	}
+	return string(result)
+}
+
+func clearAllVaults(config *Config, vaults []VaultConfig) error {
+	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
+	fmt.Printf("CLEARING VAULT DATA (TEST USE ONLY)\n")
+	fmt.Printf("%s\n", strings.Repeat("=", 80))
+
+	clients := NewEndpointClients(0)
+
+	for _, v := range vaults {
+		vc := config.ForVault(v)
+		if err := clearVaultTable(clients.For(vc.VaultURL), vc, v); err != nil {
+			fmt.Printf("  ❌ Failed to clear %s vault: %v\n", v.Name, err)
+			return err
+		}
+	}
+
+	fmt.Printf("\n✅ All vaults cleared successfully!\n")
+	return nil
+}
+
+// Logging: operational messages go through a leveled slog logger with component and vault
+// fields. The console format keeps the familiar emoji output; text and json emit structured
+// records, and everything else printed to stdout (banners, summaries) is captured into the same
+// log as component=console records. Every record passes the PII redaction layer.
+
+// logger is replaced by setupLogging once the -log-format and -log-level flags are known
+var logger = slog.New(redactingHandler{newConsoleHandler(os.Stdout, slog.LevelInfo)})
+
+// terminal is the process's original stdout, used for interactive prompts when stdout is captured
+var terminal io.Writer = os.Stdout
+
+// flushLogs drains captured stdout into the log; must run before os.Exit (safe to call twice)
+var flushLogs = func() {}
+
+// logFormats are the accepted -log-format values
+var logFormats = []string{"console", "text", "json"}
+
+// parseLogLevel parses a -log-level value (debug, info, warn or error)
+func parseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unsupported log level %q (use debug, info, warn or error)", s)
+	}
+	return level, nil
+}
+
+// setupLogging installs the logger writing to sink; text and json also capture stdout
+func setupLogging(format string, level slog.Level, sink *os.File) error {
+	var handler slog.Handler
+	switch format {
+	case "console":
+		handler = newConsoleHandler(sink, level)
+	case "text":
+		handler = slog.NewTextHandler(sink, &slog.HandlerOptions{Level: level})
+	case "json":
+		handler = slog.NewJSONHandler(sink, &slog.HandlerOptions{Level: level})
+	default:
+		return fmt.Errorf("unsupported log format %q (use %s)", format, strings.Join(logFormats, ", "))
+	}
+	logger = slog.New(redactingHandler{handler})
+	if format == "console" {
+		return nil
+	}
+	return captureStdout()
+}
+
+// captureStdout turns every line printed to stdout/stderr into a component=console log record
+// (❌ lines at error level, ⚠️ lines at warn level, separator lines dropped)
+func captureStdout() error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture stdout: %w", err)
+	}
+	os.Stdout, os.Stderr = w, w
+	log.SetOutput(w)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := strings.Trim(scanner.Text(), " \t║")
+			if strings.Trim(line, "=-─═╔╗╚╝") == "" {
+				continue
+			}
+			level := slog.LevelInfo
+			if strings.HasPrefix(line, "❌") {
+				level = slog.LevelError
+			} else if strings.HasPrefix(line, "⚠️") {
+				level = slog.LevelWarn
+			}
+			logger.Log(context.Background(), level, line, "component", "console")
+		}
+	}()
+	var once sync.Once
+	flushLogs = func() {
+		once.Do(func() {
+			w.Close()
+			<-done
+		})
+	}
+	return nil
+}
+
+// consoleIndented are the components whose records the console nests under the vault's headline output
+var consoleIndented = map[string]bool{"sender": true, "worker": true, "progress": true, "live": true}
+
+// consoleHandler prints records as the loader always has: level icon plus message, attributes
+// omitted, batch-level components indented
+type consoleHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	level  slog.Leveler
+	indent bool
+}
+
+func newConsoleHandler(w io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	indent := h.indent
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "component" {
+			indent = consoleIndented[a.Value.String()]
+		}
+		return true
+	})
+	var b strings.Builder
+	if indent {
+		b.WriteString("  ")
+	}
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("❌ ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("⚠️  ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("🔍 ")
+	}
+	b.WriteString(r.Message)
+	b.WriteByte('\n')
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	for _, a := range attrs {
+		if a.Key == "component" {
+			clone.indent = consoleIndented[a.Value.String()]
+		}
+	}
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// redactingHandler scrubs PII-looking text from messages and string/error attributes before
+// they reach the output handler
+type redactingHandler struct {
+	inner slog.Handler
+}
+
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redactPII(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return redactingHandler{h.inner.WithAttrs(redacted)}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.inner.WithGroup(name)}
+}
+
+// redactAttr scrubs string, error and other free-form attribute values (numbers and times pass through)
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redactPII(v.String()))
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, redactPII(err.Error()))
+		}
+		return slog.String(a.Key, redactPII(fmt.Sprint(v.Any())))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, member := range group {
+			redacted[i] = redactAttr(member)
+		}
+		return slog.Group(a.Key, redacted...)
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// piiPatterns catch sensitive values in text the loader did not build itself
+var piiPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`), "Bearer [REDACTED]"}, // Credential-length only, so "Bearer token rejected" survives
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), "[REDACTED_JWT]"},
+	{regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[REDACTED_SSN]"},
+	{regexp.MustCompile(`\b\d{13,19}\b`), "[REDACTED_NUMBER]"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+}
+
+// redactPII masks bearer tokens, JWTs, SSNs, card-length numbers and email addresses
+func redactPII(s string) string {
+	for _, p := range piiPatterns {
+		s = p.re.ReplaceAllString(s, p.replacement)
+	}
+	return s
+}
+
+// responseSafeKeys are API response fields whose string values are kept (after redactPII)
+var responseSafeKeys = map[string]bool{
+	"message": true, "http_status": true, "grpc_code": true, "http_code": true, "code": true, "status": true,
+}
+
+// redactResponseBody makes an API response body safe to log or put in an error: JSON string
+// values are masked except for error message fields, and the batch's submitted values and tokens
+// are scrubbed from whatever text remains
+func redactResponseBody(body []byte, batch []Record) string {
+	text := string(body)
+	var parsed any
+	if json.Unmarshal(body, &parsed) == nil {
+		if data, err := json.Marshal(redactJSONValue("", parsed)); err == nil {
+			text = string(data)
+		}
+	}
+	return redactPII(redactBatchValues(text, batch))
+}
+
+// redactJSONValue replaces string leaves with their length unless their key is in responseSafeKeys
+func redactJSONValue(key string, v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			t[k] = redactJSONValue(k, child)
+		}
+		return t
+	case []any:
+		for i, child := range t {
+			t[i] = redactJSONValue(key, child)
+		}
+		return t
+	case string:
+		if responseSafeKeys[key] {
+			return t
+		}
+		return fmt.Sprintf("[REDACTED len=%d]", utf8.RuneCountInString(t))
+	}
+	return v
+}
+
+// redactBatchValues replaces every submitted value and token of the batch (4+ characters) in text
+func redactBatchValues(text string, batch []Record) string {
+	if len(batch) == 0 {
+		return text
+	}
+	var secrets []string
+	add := func(value, token string) {
+		if len(value) >= 4 {
+			secrets = append(secrets, value)
+		}
+		if len(token) >= 4 {
+			secrets = append(secrets, token)
+		}
+	}
+	for _, r := range batch {
+		add(r.Value, r.Token)
+		for _, f := range r.Fields {
+			add(f.Value, f.Token)
+		}
+	}
+	// Longest first so a value containing another is replaced whole
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, "[REDACTED]")
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// setupOfflineMode creates a log file and redirects stdout/stderr to it
+func setupOfflineMode(logFilename string) (*os.File, error) {
+	// Create log file
+	logFile, err := os.OpenFile(logFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+
+	// Redirect stdout and stderr to log file
+	os.Stdout = logFile
+	os.Stderr = logFile
+
+	// Also set up Go's log package to use the file
+	log.SetOutput(logFile)
+
+	return logFile, nil
+}
+
+// Runtime control: an opt-in HTTP endpoint on a Unix socket (-control) or loopback address
+// (-control-addr) that pauses and resumes a running load and retunes its concurrency, batch size
+// and base delay. The ctl subcommand finds the socket through the PID file.
+
+// controller is nil unless the control API is enabled; every LoadController method is nil-safe
+var controller *LoadController
+
+// controlMaxConcurrency is the highest concurrency the control API accepts (above the configured
+// value); each vault's connection pool is sized for it up front, since the HTTP client is shared
+// by the vault's workers and can't grow once the load has started
+const controlMaxConcurrency = 1000
+
+// ControlSettings are the live overrides (nil: the vault's configured value); also the /set request body
+type ControlSettings struct {
+	Concurrency *int `json:"concurrency,omitempty"`
+	BatchSize   *int `json:"batch_size,omitempty"`
+	BaseDelayMs *int `json:"base_delay_ms,omitempty"`
+}
+
+// ControlState is the /state response
+type ControlState struct {
+	PID               int             `json:"pid"`
+	Paused            bool            `json:"paused"`
+	PausedSeconds     float64         `json:"paused_seconds,omitempty"`
+	Vault             string          `json:"vault,omitempty"`
+	RecordsPlanned    int64           `json:"records_planned"`
+	RecordsLoaded     int64           `json:"records_loaded"`
+	BatchesSuccessful int64           `json:"batches_successful"`
+	BatchesFailed     int64           `json:"batches_failed"`
+	ActiveWorkers     int64           `json:"active_workers"`
+	InFlight          int64           `json:"http_in_flight"`
+	Concurrency       int             `json:"concurrency,omitempty"`
+	BatchSize         int             `json:"batch_size,omitempty"`
+	BaseDelayMs       int64           `json:"base_delay_ms"`
+	Overrides         ControlSettings `json:"overrides"`
+}
+
+// LoadController holds the live overrides and gates workers while paused or above the concurrency limit
+type LoadController struct {
+	Addr       string // Listen address ("unix:<path>" for the socket)
+	mu         sync.Mutex
+	cond       *sync.Cond
+	paused     bool
+	pausedAt   time.Time
+	overrides  ControlSettings
+	active     int     // Workers holding a slot
+	config     *Config // Settings of the vault being loaded (nil between vaults)
+	metrics    *Metrics
+	server     *http.Server
+	socketPath string
+	token      string // Required as "Authorization: Bearer <token>" on -control-addr
+	tokenPath  string
+}
+
+// controlSocketPath is where -control listens for the loader with the given PID. The socket
+// lives in its own directory so it can be created inside mode 0700 instead of chmodded afterwards.
+func controlSocketPath(pid int) string {
+	return filepath.Join(fmt.Sprintf("skyflow-loader-%d", pid), "control.sock")
+}
+
+// controlTokenPath is where -control-addr writes the API token for the loader with the given PID
+func controlTokenPath(pid int) string {
+	return fmt.Sprintf("skyflow-loader-%d.token", pid)
+}
+
+// NewLoadController creates a controller with no overrides
+func NewLoadController() *LoadController {
+	c := &LoadController{}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Start listens on the Unix socket, or on addr (which must be a loopback address), and serves the API.
+// Any local user can reach a loopback port, so addr requires a random token, written to
+// controlTokenPath (mode 0600) for ctl. The socket needs none: its directory is created mode 0700
+// before the socket exists, so no other user can ever connect to it.
+func (c *LoadController) Start(socketPath, addr string) error {
+	var listener net.Listener
+	var err error
+	if socketPath != "" {
+		dir := filepath.Dir(socketPath)
+		if err := os.Mkdir(dir, 0700); err != nil {
+			// Reuse a directory left behind by a crashed run only if it is ours to lock down
+			fi, statErr := os.Lstat(dir)
+			if !os.IsExist(err) || statErr != nil || !fi.IsDir() {
+				return fmt.Errorf("failed to create control socket directory %s: %w", dir, err)
+			}
+			if err := os.Chmod(dir, 0700); err != nil {
+				return fmt.Errorf("failed to restrict %s: %w", dir, err)
+			}
+		}
+		// Replace a socket left behind by a crashed run, never any other file
+		if fi, statErr := os.Lstat(socketPath); statErr == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(socketPath)
+		}
+		listener, err = net.Listen("unix", socketPath)
+		if err != nil {
+			os.Remove(dir)
+			return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+		}
+		c.socketPath = socketPath
+		c.Addr = "unix:" + socketPath
 	} else {
-		fmt.Printf("   Estimated Time: ~%.1f hours\n", estimatedSeconds/3600)
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return fmt.Errorf("invalid control address %q: %w", addr, err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("control address %q must be a loopback address (e.g. 127.0.0.1:9103)", addr)
+		}
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return fmt.Errorf("failed to generate control token: %w", err)
+		}
+		tokenPath := controlTokenPath(os.Getpid())
+		if err := os.WriteFile(tokenPath, []byte(hex.EncodeToString(secret)+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write control token: %w", err)
+		}
+		listener, err = net.Listen("tcp", addr)
+		if err != nil {
+			os.Remove(tokenPath)
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		c.token, c.tokenPath = hex.EncodeToString(secret), tokenPath
+		c.Addr = listener.Addr().String()
 	}
-	fmt.Printf("   (Actual time may vary based on API response times and rate limits)\n")
 
-	// Confirmation prompt
-	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
-	fmt.Printf("⚠️  WARNING: This will attempt to reprocess %s records.\n", formatNumber(len(errorLogSource.Records)))
-	if !config.Upsert {
-		fmt.Printf("⚠️  Upsert is DISABLED - records may fail if they already exist.\n")
-		fmt.Printf("    Consider adding the -upsert flag if you want to update existing records.\n")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /state", func(w http.ResponseWriter, r *http.Request) {
+		c.respond(w, nil)
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		c.Pause()
+		c.respond(w, nil)
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		c.Resume()
+		c.respond(w, nil)
+	})
+	mux.HandleFunc("POST /reset", func(w http.ResponseWriter, r *http.Request) {
+		c.Set(ControlSettings{}, true)
+		c.respond(w, nil)
+	})
+	mux.HandleFunc("POST /set", func(w http.ResponseWriter, r *http.Request) {
+		var settings ControlSettings
+		decoder := json.NewDecoder(io.LimitReader(r.Body, 64*1024))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&settings); err != nil {
+			c.respond(w, fmt.Errorf("invalid settings: %w", err))
+			return
+		}
+		c.respond(w, c.Set(settings, false))
+	})
+	var handler http.Handler = mux
+	if c.token != "" {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+c.token)) != 1 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				json.NewEncoder(w).Encode(map[string]string{"error": "missing or wrong control token (see " + c.tokenPath + ")"})
+				return
+			}
+			mux.ServeHTTP(w, r)
+		})
+	}
+	c.server = &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go c.server.Serve(listener)
+	return nil
+}
+
+// respond writes the current state, or the error with status 400
+func (c *LoadController) respond(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(c.State())
+}
+
+// Close stops the server and removes the socket or token file
+func (c *LoadController) Close() {
+	if c == nil || c.server == nil {
+		return
+	}
+	c.server.Close()
+	if c.socketPath != "" {
+		os.Remove(c.socketPath)
+		os.Remove(filepath.Dir(c.socketPath)) // Only succeeds once empty
+	}
+	if c.tokenPath != "" {
+		os.Remove(c.tokenPath)
+	}
+}
+
+// Attach makes config/metrics the vault the state reports on (nil-safe)
+func (c *LoadController) Attach(config *Config, metrics *Metrics) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config, c.metrics = config, metrics
+}
+
+// Detach clears the current vault once it is done (nil-safe)
+func (c *LoadController) Detach() {
+	c.Attach(nil, nil)
+}
+
+// Pause stops workers from starting new batches; in-flight batches finish
+func (c *LoadController) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return
+	}
+	c.paused = true
+	c.pausedAt = time.Now()
+	logger.Info("⏸️  Paused by control API: in-flight batches finish, no new batches start", "component", "control")
+}
+
+// Resume lets workers start batches again
+func (c *LoadController) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	logger.Info(fmt.Sprintf("▶️  Resumed by control API after %s", time.Since(c.pausedAt).Round(time.Second)),
+		"component", "control", "paused_seconds", time.Since(c.pausedAt).Seconds())
+	c.cond.Broadcast()
+}
+
+// Set applies the given overrides (reset: clear all overrides first)
+func (c *LoadController) Set(settings ControlSettings, reset bool) error {
+	if v := settings.Concurrency; v != nil && *v <= 0 {
+		return fmt.Errorf("concurrency must be positive, got %d", *v)
+	}
+	if v := settings.Concurrency; v != nil && *v > controlMaxConcurrency {
+		return fmt.Errorf("concurrency above %d can't be set at run time, got %d", controlMaxConcurrency, *v)
+	}
+	if v := settings.BatchSize; v != nil && *v <= 0 {
+		return fmt.Errorf("batch_size must be positive, got %d", *v)
+	}
+	if v := settings.BaseDelayMs; v != nil && *v < 0 {
+		return fmt.Errorf("base_delay_ms must not be negative, got %d", *v)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reset {
+		c.overrides = ControlSettings{}
+	}
+	var changes []string
+	if v := settings.Concurrency; v != nil {
+		c.overrides.Concurrency = v
+		changes = append(changes, fmt.Sprintf("concurrency %d", *v))
+	}
+	if v := settings.BatchSize; v != nil {
+		c.overrides.BatchSize = v
+		changes = append(changes, fmt.Sprintf("batch size %d", *v))
+	}
+	if v := settings.BaseDelayMs; v != nil {
+		c.overrides.BaseDelayMs = v
+		changes = append(changes, fmt.Sprintf("base delay %dms", *v))
+	}
+	if reset {
+		changes = append(changes, "configured settings restored")
+	}
+	logger.Info("🎛️  Control API: "+strings.Join(changes, ", "), "component", "control")
+	c.cond.Broadcast()
+	return nil
+}
+
+// Concurrency is the worker limit: the override, or configured (nil-safe)
+func (c *LoadController) Concurrency(configured int) int {
+	if c == nil {
+		return configured
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.concurrency(configured)
+}
+
+// MaxConcurrency is the most workers a vault can reach, which its connection pool is sized for:
+// configured, or up to controlMaxConcurrency when the control API can raise it (nil-safe)
+func (c *LoadController) MaxConcurrency(configured int) int {
+	if c == nil {
+		return configured
+	}
+	return max(configured, controlMaxConcurrency)
+}
+
+func (c *LoadController) concurrency(configured int) int {
+	if c.overrides.Concurrency != nil {
+		return *c.overrides.Concurrency
+	}
+	return configured
+}
+
+// BatchSize is the size of batches not yet queued: the override, or configured (nil-safe)
+func (c *LoadController) BatchSize(configured int) int {
+	if c == nil {
+		return configured
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overrides.BatchSize != nil {
+		return *c.overrides.BatchSize
+	}
+	return configured
+}
+
+// BaseDelay is the delay before each request: the override, or configured (nil-safe)
+func (c *LoadController) BaseDelay(configured time.Duration) time.Duration {
+	if c == nil {
+		return configured
 	}
-	fmt.Printf("%s\n\n", strings.Repeat("=", 80))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overrides.BaseDelayMs != nil {
+		return time.Duration(*c.overrides.BaseDelayMs) * time.Millisecond
+	}
+	return configured
+}
 
-	// Prompt for confirmation
-	response, err := promptForInput("Do you want to proceed? (yes/no): ")
-	if err != nil {
-		return false, err
+// Paused reports whether the load is paused (nil-safe)
+func (c *LoadController) Paused() bool {
+	if c == nil {
+		return false
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
 
-	response = strings.ToLower(strings.TrimSpace(response))
-	return response == "yes" || response == "y", nil
+// Acquire blocks a worker until the load is not paused and fewer than the concurrency limit
+// (configured unless overridden) hold a slot (nil-safe)
+func (c *LoadController) Acquire(configured int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.paused || c.active >= c.concurrency(configured) {
+		c.cond.Wait()
+	}
+	c.active++
 }
 
-// formatNumber formats an integer with comma separators
-func formatNumber(n int) string {
-	s := strconv.Itoa(n)
-	if len(s) <= 3 {
-		return s
+// Release frees the slot taken by Acquire (nil-safe)
+func (c *LoadController) Release() {
+	if c == nil {
+		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active--
+	c.cond.Broadcast()
+}
 
-	// Add commas from right to left
-	var result []byte
-	for i, digit := range s {
-		if i > 0 && (len(s)-i)%3 == 0 {
-			result = append(result, ',')
+// State reports the pause state, effective settings and progress of the current vault
+func (c *LoadController) State() ControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := ControlState{PID: os.Getpid(), Paused: c.paused, Overrides: c.overrides}
+	if c.paused {
+		state.PausedSeconds = time.Since(c.pausedAt).Seconds()
+	}
+	if m := c.metrics; m != nil {
+		state.Vault = m.VaultName
+		state.RecordsPlanned = atomic.LoadInt64(&m.PlannedRecords)
+		state.RecordsLoaded = atomic.LoadInt64(&m.TotalRecords)
+		state.BatchesSuccessful = atomic.LoadInt64(&m.SuccessfulBatches)
+		state.BatchesFailed = atomic.LoadInt64(&m.FailedBatches)
+		state.ActiveWorkers = atomic.LoadInt64(&m.ActiveWorkers)
+		state.InFlight = atomic.LoadInt64(&m.ActiveRequests)
+	}
+	if cfg := c.config; cfg != nil {
+		state.Concurrency = c.concurrency(cfg.MaxConcurrency)
+		state.BatchSize = cfg.BatchSize
+		if c.overrides.BatchSize != nil {
+			state.BatchSize = *c.overrides.BatchSize
 		}
-		result = append(result, byte(digit))
+		delay := cfg.BaseRequestDelay
+		if c.overrides.BaseDelayMs != nil {
+			delay = time.Duration(*c.overrides.BaseDelayMs) * time.Millisecond
+		}
+		state.BaseDelayMs = delay.Milliseconds()
 	}
-	return string(result)
+	return state
 }
 
-func clearAllVaults(config *Config, vaults []VaultConfig) error {
-	fmt.Printf("\n%s\n", strings.Repeat("=", 80))
-	fmt.Printf("CLEARING VAULT DATA (TEST USE ONLY)\n")
-	fmt.Printf("%s\n", strings.Repeat("=", 80))
+// runCtl implements the ctl subcommand: send a control command to a running loader and print its state
+func runCtl(args []string) int {
+	fs := flag.NewFlagSet("ctl", flag.ExitOnError)
+	pidFile := fs.String("pid-file", "skyflow-loader.pid", "PID file of the running loader")
+	socket := fs.String("socket", "", "Control socket (default: skyflow-loader-<pid>/control.sock for the PID in -pid-file)")
+	addr := fs.String("addr", "", "Control address of a loader started with -control-addr")
+	tokenFile := fs.String("token-file", "", "Control token for -addr (default: skyflow-loader-<pid>.token for the PID in -pid-file)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), `Usage: %s ctl [flags] <command>
+
+Commands:
+  state                       Show pause state, effective settings and progress (default)
+  pause                       Stop starting new batches (in-flight batches finish)
+  resume                      Continue a paused load
+  set key=value...            Change concurrency, batch_size and/or base_delay_ms
+  reset                       Drop all overrides and go back to the configured settings
+
+Flags:
+`, os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
 
-	client := createHTTPClient(0)
+	command := "state"
+	if fs.NArg() > 0 {
+		command = fs.Arg(0)
+	}
+	method, path := http.MethodPost, "/"+command
+	var body io.Reader
+	switch command {
+	case "state":
+		method = http.MethodGet
+	case "pause", "resume", "reset":
+	case "set":
+		settings, err := parseControlSettings(fs.Args()[1:])
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return exitFatal
+		}
+		data, _ := json.Marshal(settings)
+		body = bytes.NewReader(data)
+	default:
+		fmt.Printf("❌ Unknown ctl command %q\n", command)
+		fs.Usage()
+		return exitFatal
+	}
 
-	for _, v := range vaults {
-		if err := clearVaultTable(client, config, v); err != nil {
-			fmt.Printf("  ❌ Failed to clear %s vault: %v\n", v.Name, err)
-			return err
+	// The socket and token file are named after the PID of the loader in the PID file
+	loaderPID := func() (int, error) {
+		data, err := os.ReadFile(*pidFile)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read PID file (is a loader running with -control in this directory?): %w", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid PID file %s: %w", *pidFile, err)
+		}
+		if err := syscall.Kill(pid, 0); err != nil {
+			return 0, fmt.Errorf("loader process %d is not running (stale PID file %s?)", pid, *pidFile)
 		}
+		return pid, nil
 	}
 
-	fmt.Printf("\n✅ All vaults cleared successfully!\n")
-	return nil
-}
+	// Loopback address (with its token), or the socket of the loader in the PID file
+	client := &http.Client{Timeout: 10 * time.Second}
+	baseURL := "http://" + *addr
+	token := ""
+	if *addr != "" {
+		path := *tokenFile
+		if path == "" {
+			pid, err := loaderPID()
+			if err != nil {
+				fmt.Printf("❌ %v (or pass -token-file)\n", err)
+				return exitFatal
+			}
+			path = controlTokenPath(pid)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("❌ Failed to read control token: %v\n", err)
+			return exitFatal
+		}
+		token = strings.TrimSpace(string(data))
+	} else {
+		socketPath := *socket
+		if socketPath == "" {
+			pid, err := loaderPID()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return exitFatal
+			}
+			socketPath = controlSocketPath(pid)
+		}
+		client.Transport = &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+		}}
+		baseURL = "http://skyflow-loader"
+	}
 
-// setupOfflineMode creates a log file and redirects stdout/stderr to it
-func setupOfflineMode(logFilename string) (*os.File, error) {
-	// Create log file
-	logFile, err := os.OpenFile(logFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	req, err := http.NewRequest(method, baseURL+path, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create log file: %w", err)
+		fmt.Printf("❌ %v\n", err)
+		return exitFatal
 	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Printf("❌ Control API unreachable (was the loader started with -control or -control-addr?): %v\n", err)
+		return exitFatal
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			fmt.Printf("❌ %s\n", apiErr.Error)
+		} else {
+			fmt.Printf("❌ Control API returned status %d\n", resp.StatusCode)
+		}
+		return exitFatal
+	}
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, data, "", "  ") != nil {
+		pretty.Write(data)
+	}
+	fmt.Println(strings.TrimSpace(pretty.String()))
+	return exitSuccess
+}
 
-	// Redirect stdout and stderr to log file
-	os.Stdout = logFile
-	os.Stderr = logFile
-
-	// Also set up Go's log package to use the file
-	log.SetOutput(logFile)
-
-	return logFile, nil
+// parseControlSettings parses ctl set arguments (concurrency=N, batch_size=N, base_delay_ms=N)
+func parseControlSettings(args []string) (ControlSettings, error) {
+	var settings ControlSettings
+	if len(args) == 0 {
+		return settings, fmt.Errorf("ctl set needs at least one of concurrency=N, batch_size=N, base_delay_ms=N")
+	}
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		n, err := strconv.Atoi(value)
+		if !ok || err != nil {
+			return settings, fmt.Errorf("invalid setting %q (use key=number)", arg)
+		}
+		switch strings.ReplaceAll(key, "-", "_") {
+		case "concurrency":
+			settings.Concurrency = &n
+		case "batch_size":
+			settings.BatchSize = &n
+		case "base_delay_ms":
+			settings.BaseDelayMs = &n
+		default:
+			return settings, fmt.Errorf("unknown setting %q (use concurrency, batch_size or base_delay_ms)", key)
+		}
+	}
+	return settings, nil
 }
 
 // createPIDFile writes the current process ID to a file
@@ -1866,26 +8569,54 @@ func removePIDFile() {
 	os.Remove(pidFile) // Ignore errors
 }
 
-// setupSignalHandler sets up handler to ignore SIGHUP (SSH disconnect)
-func setupSignalHandler() {
+// setupSignalHandler stops the run on SIGINT or SIGTERM (Ctrl-C, kill) through exitRun, so the
+// fatal webhook, history entry, run report and traces are written and main's cleanups run. With
+// ignoreHangup (offline mode), SIGHUP from an SSH disconnect is logged and otherwise ignored.
+func setupSignalHandler(ignoreHangup bool) {
 	sigChan := make(chan os.Signal, 1)
-
-	// Ignore SIGHUP (SSH disconnect) - process continues running
-	signal.Notify(sigChan, syscall.SIGHUP)
+	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	if ignoreHangup {
+		signals = append(signals, syscall.SIGHUP)
+	}
+	signal.Notify(sigChan, signals...)
 
 	go func() {
 		for sig := range sigChan {
 			if sig == syscall.SIGHUP {
 				fmt.Println("Received SIGHUP (SSH disconnect) - continuing in background...")
+				continue
 			}
+			// A second signal while finishing falls back to the default (immediate exit)
+			signal.Reset(syscall.SIGINT, syscall.SIGTERM)
+			name := "SIGTERM"
+			if sig == syscall.SIGINT {
+				name = "SIGINT"
+			}
+			fmt.Printf("\n❌ Received %s, stopping the run\n", name)
+			exitRun(exitFatal, "interrupted by "+name)
 		}
 	}()
 }
 
 func main() {
+	// Subcommand: talk to a running loader's control API
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		os.Exit(runCtl(os.Args[2:]))
+	}
+	// Subcommand: list, show and compare recorded runs
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		os.Exit(runHistory(os.Args[2:]))
+	}
+
 	// Command-line flags
 	configFile := flag.String("config", "config.json", "Path to configuration file")
 	bearerToken := flag.String("token", "", "Bearer token for authentication (overrides config, optional if set in config.json)")
+	proxyURL := flag.String("proxy", "", "HTTP(S) or SOCKS5 proxy for Skyflow calls (overrides config network.proxy_url)")
+	caBundle := flag.String("ca-bundle", "", "PEM file of extra root CAs to trust (overrides config network.ca_bundle)")
+	clientCert := flag.String("client-cert", "", "PEM client certificate for mutual TLS (overrides config network.client_cert)")
+	clientKey := flag.String("client-key", "", "PEM private key for -client-cert (overrides config network.client_key)")
+	minTLSVersion := flag.String("min-tls-version", "", "Minimum TLS version: 1.2 or 1.3 (overrides config network.min_tls_version)")
+	credentialsFile := flag.String("credentials", "", "Path to Skyflow service account credentials.json (mints and refreshes bearer tokens, overrides config)")
 
 	// Override flags (optional - override config file values)
 	vaultURL := flag.String("vault-url", "", "Skyflow vault URL (overrides config)")
@@ -1917,16 +8648,81 @@ func main() {
 	maxConcurrency := flag.Int("concurrency", 0, "Maximum concurrent requests per vault (overrides config)")
 	maxRecords := flag.Int("max-records", -1, "Maximum records to process (overrides config, -1 uses config)")
 	appendSuffix := flag.Bool("append-suffix", false, "Append unique suffix to data/tokens")
+	suffixStrategy := flag.String("suffix-strategy", "", "Suffix for -append-suffix: random, deterministic (HMAC of value keyed by the run tag) or run-tag (overrides config)")
+	runTag := flag.String("run-tag", "", "Run tag for deterministic/run-tag suffixes (overrides config; deterministic default: run<timestamp>)")
 	baseDelay := flag.Int("base-delay-ms", -1, "Base delay between requests in milliseconds (overrides config, -1 uses config)")
 	upsertFlag := flag.Bool("upsert", false, "Enable upsert mode (update existing records)")
+	compressFlag := flag.String("compress", "", "Compress request bodies: gzip, zstd or none (overrides config)")
+	compressLevel := flag.Int("compress-level", -1, "Request compression level: gzip 1-9, zstd 1-22, 0 = default (overrides config)")
 
 	// Other flags
 	vault := flag.String("vault", "", "Process only specific vault (name, id, dob, ssn)")
 	clearVaults := flag.Bool("clear", false, "Clear all data from vaults before loading (TEST USE ONLY)")
 	offlineMode := flag.Bool("offline", false, "Run in offline mode: output to log file, survive SSH disconnect")
+	dryRun := flag.Bool("dry-run", false, "Write BYOT payloads to a file instead of sending them")
+	dryRunOutput := flag.String("dry-run-output", "", "Dry-run NDJSON output file (default: dry_run_<timestamp>.ndjson)")
+	dryRunGzip := flag.Bool("dry-run-gzip", false, "Gzip-compress the dry-run output file")
+	dryRunRedact := flag.Bool("dry-run-redact", false, "Redact values and tokens in the dry-run output (keeps lengths)")
+	preflight := flag.Bool("preflight", false, "Validate vault schemas and sample token formats before loading (fails fast)")
+	preflightSample := flag.Int("preflight-sample", 1000, "Records per vault sampled at random from the source for the pre-flight token format check (0 = all)")
+	managementURL := flag.String("management-url", "", "Skyflow management API URL for pre-flight schema checks (overrides config)")
+	verifyMode := flag.Bool("verify", false, "Verify a completed load: detokenize sampled source tokens and compare to source values (no data is loaded)")
+	verifySample := flag.Int("verify-sample", 1000, "Records per vault to verify (0 = all)")
+	verifyReport := flag.String("verify-report", "", "Verification report file (default: verify_report_<timestamp>.json)")
+	reconcileMode := flag.Bool("reconcile", false, "Compare distinct source value/token pairs with vault record counts (no data is loaded)")
+	reconcileReport := flag.String("reconcile-report", "", "Reconciliation report file (default: reconcile_report_<timestamp>.json)")
+	crosswalkFile := flag.String("crosswalk", "", "Write value-hash/token/skyflow_id crosswalk for inserted records to this CSV or NDJSON file")
+	crosswalkFormat := flag.String("crosswalk-format", "", "Crosswalk file format: csv or ndjson (default: from file extension)")
+	dedupMode := flag.String("dedup", "", "Deduplicate records before batching: off, memory or disk (overrides config)")
+	dedupPolicy := flag.String("dedup-policy", "", "On token/value conflicts: skip, fail or keep-first (overrides config, default keep-first)")
+	dedupDir := flag.String("dedup-dir", "", "Directory for -dedup disk buckets (default: system temp dir)")
+	conflictReport := flag.String("conflict-report", "", "Duplicate/conflict report file (default: conflict_report_<timestamp>.json)")
+	rejectFile := flag.String("reject-file", "", "Write records failing validation rules to this NDJSON file (default: rejects_<timestamp>.ndjson)")
+	crosswalkTable := flag.String("crosswalk-table", "", "Write the skyflow_id crosswalk to this Snowflake table (created if missing)")
+	metricsAddr := flag.String("metrics-addr", "", "Serve Prometheus metrics for the load on this address (e.g. :9102)")
+	reportFile := flag.String("report-file", "", "Write a machine-readable JSON run report to this file")
+	historyFile := flag.String("history-file", "", "Append each run's settings and results to this run history file (default: off; 'history' reads "+defaultHistoryFile+")")
+	logFormat := flag.String("log-format", "", "Log format: console, text or json (default: console, json with -offline)")
+	logLevel := flag.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	traceOTLP := flag.String("trace-otlp", "", "Export OpenTelemetry spans to this OTLP/HTTP collector (e.g. http://localhost:4318)")
+	traceFile := flag.String("trace-file", "", "Write OpenTelemetry spans to this OTLP/JSON Lines file")
+	webhookURL := flag.String("webhook", "", "Post start, progress, completion and fatal-error notifications to this webhook URL (adds to config notifications)")
+	webhookFormat := flag.String("webhook-format", "json", "Payload template for -webhook: json or slack")
+	webhookProgress := flag.Int("webhook-progress-minutes", 0, "Minutes between progress notifications (overrides config, default 30)")
+	controlSocket := flag.Bool("control", false, "Serve the runtime control API on skyflow-loader-<pid>/control.sock (use the ctl subcommand to pause, resume and retune)")
+	controlAddr := flag.String("control-addr", "", "Serve the runtime control API on this loopback address instead (e.g. 127.0.0.1:9103)")
+	traceSample := flag.Float64("trace-sample", 1, "Fraction of batches traced with -trace-otlp/-trace-file (0-1; run and vault spans are always kept)")
 
 	flag.Parse()
 
+	// Machine-readable run report (written when -report-file is set); exitCode is applied after
+	// every other deferred cleanup has run
+	runReport = NewRunReport(*reportFile)
+	runReport.HistoryPath = *historyFile
+	exitCode := exitSuccess
+	defer func() {
+		flushLogs()
+		if exitCode != exitSuccess {
+			os.Exit(exitCode)
+		}
+	}()
+	defer runCleanups()
+
+	// Validate logging flags before any output is redirected
+	if *logFormat == "" {
+		*logFormat = "console"
+		if *offlineMode {
+			*logFormat = "json"
+		}
+	}
+	if !slices.Contains(logFormats, *logFormat) {
+		fatalf("Invalid -log-format %q (use %s)", *logFormat, strings.Join(logFormats, ", "))
+	}
+	minLogLevel, err := parseLogLevel(*logLevel)
+	if err != nil {
+		fatalf("Invalid -log-level: %v", err)
+	}
+
 	// Setup offline mode if requested (must be done before any other output)
 	var logFile *os.File
 	var logFilename string
@@ -1964,53 +8760,124 @@ You can now safely disconnect from SSH. The process will continue running.
 			fmt.Fprintf(os.Stderr, "❌ Failed to setup offline mode: %v\n", err)
 			os.Exit(1)
 		}
-		defer logFile.Close()
+		defer func() {
+			flushLogs()
+			logFile.Close()
+		}()
+
+		// Structured logs go to the log file, including everything printed below
+		if err := setupLogging(*logFormat, minLogLevel, logFile); err != nil {
+			fatalf("Failed to set up logging: %v", err)
+		}
 
 		// Create PID file
 		if err := createPIDFile(); err != nil {
 			fmt.Printf("⚠️  Warning: Failed to create PID file: %v\n", err)
 		} else {
-			defer removePIDFile()
+			atExit(removePIDFile)
 		}
 
-		// Set up signal handler to ignore SIGHUP
-		setupSignalHandler()
-
 		// All subsequent output now goes to log file
 		fmt.Printf("╔════════════════════════════════════════════════════════════════╗\n")
 		fmt.Printf("║          SKYFLOW BYOT LOADER - OFFLINE MODE STARTED            ║\n")
 		fmt.Printf("╚════════════════════════════════════════════════════════════════╝\n\n")
 		fmt.Printf("Log File: %s\n", logFilename)
+		if runReport != nil {
+			runReport.Artifacts.LogFile = logFilename
+		}
 		fmt.Printf("Process ID: %d\n", os.Getpid())
 		fmt.Printf("Started: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
 	}
 
+	if !*offlineMode {
+		if err := setupLogging(*logFormat, minLogLevel, os.Stdout); err != nil {
+			fatalf("Failed to set up logging: %v", err)
+		}
+	}
+
+	// Finish the run cleanly on SIGINT/SIGTERM; offline mode also ignores SIGHUP
+	setupSignalHandler(*offlineMode)
+
 	// Load configuration file
 	fmt.Printf("📋 Loading configuration from: %s\n", *configFile)
 	fileConfig, err := loadConfigFile(*configFile)
 	if err != nil {
-		fmt.Printf("❌ Failed to load config file: %v\n", err)
-		fmt.Printf("   Make sure config.json exists in the current directory\n")
-		os.Exit(1)
+		fatalf("Failed to load config file: %v\n   Make sure config.json exists in the current directory", err)
+	}
+
+	// Proxy, TLS trust, client certificates and timeouts for every Skyflow HTTP client
+	// (configured before the first client is created, including the token minting client)
+	networkConfig := fileConfig.Network
+	for _, override := range []struct {
+		cli    string
+		target *string
+	}{
+		{*proxyURL, &networkConfig.ProxyURL},
+		{*caBundle, &networkConfig.CABundle},
+		{*clientCert, &networkConfig.ClientCert},
+		{*clientKey, &networkConfig.ClientKey},
+		{*minTLSVersion, &networkConfig.MinTLSVersion},
+	} {
+		if override.cli != "" {
+			*override.target = override.cli
+		}
+	}
+	settings, err := NewNetworkSettings(networkConfig)
+	if err != nil {
+		fatalf("Invalid network configuration: %v", err)
+	}
+	networkSettings = settings
+	if description := settings.Describe(networkConfig); description != "" {
+		fmt.Printf("🌐 Network: %s\n", description)
+	}
+
+	// Vaults with their own credentials don't need the run-wide token
+	selectedVault := func(v VaultConfig) bool {
+		return *vault == "" || strings.EqualFold(v.Name, *vault)
+	}
+	needsRunToken := false
+	for _, v := range fileConfig.Skyflow.Vaults {
+		if selectedVault(v) && !v.HasCredentials() {
+			needsRunToken = true
+		}
 	}
 
-	// Use bearer token from command line, config file, or prompt
-	finalBearerToken := *bearerToken
-	if finalBearerToken == "" {
-		finalBearerToken = fileConfig.Skyflow.BearerToken
+	// Use bearer token from command line, service account credentials, config file, or prompt
+	var authProvider *TokenProvider
+	finalCredentialsFile := *credentialsFile
+	if finalCredentialsFile == "" {
+		finalCredentialsFile = fileConfig.Skyflow.CredentialsFile
 	}
-	if finalBearerToken == "" {
-		// Prompt for bearer token
-		token, err := promptForPassword("🔑 Enter Skyflow bearer token: ")
+	if *bearerToken == "" && finalCredentialsFile != "" {
+		provider, err := NewServiceAccountTokenProvider(finalCredentialsFile)
 		if err != nil {
-			fmt.Printf("❌ Error reading bearer token: %v\n", err)
-			os.Exit(1)
+			fatalf("Failed to load service account credentials: %v", err)
 		}
-		if token == "" {
-			fmt.Println("❌ Error: Bearer token is required")
-			os.Exit(1)
+		// Mint the first token up front so bad credentials fail before any data is read
+		// (dry-runs never call the API, so they skip this)
+		if !*dryRun {
+			if _, err := provider.Token(); err != nil {
+				fatalf("Failed to obtain bearer token from service account: %v", err)
+			}
+		}
+		authProvider = provider
+	} else {
+		finalBearerToken := *bearerToken
+		if finalBearerToken == "" {
+			finalBearerToken = fileConfig.Skyflow.BearerToken
+		}
+		if finalBearerToken == "" && !*dryRun && needsRunToken {
+			// Prompt for bearer token
+			token, err := promptForPassword("🔑 Enter Skyflow bearer token: ")
+			if err != nil {
+				fatalf("Error reading bearer token: %v", err)
+			}
+			if token == "" {
+				fatalf("Error: Bearer token is required")
+			}
+			finalBearerToken = token
 		}
-		finalBearerToken = token
+		authProvider = NewStaticTokenProvider(finalBearerToken)
 	}
 
 	// Build runtime config with CLI overrides
@@ -2041,17 +8908,15 @@ You can now safely disconnect from SSH. The process will continue running.
 	finalSnowflakeUser := overrideString(*sfUser, fileConfig.Snowflake.User)
 	finalSnowflakePassword := overrideString(*sfPassword, fileConfig.Snowflake.Password)
 
-	if dataSourceValue == "snowflake" {
+	if dataSourceValue == "snowflake" || *crosswalkTable != "" {
 		// Prompt for Snowflake user if missing
 		if finalSnowflakeUser == "" {
 			user, err := promptForInput("❄️  Enter Snowflake username: ")
 			if err != nil {
-				fmt.Printf("❌ Error reading Snowflake username: %v\n", err)
-				os.Exit(1)
+				fatalf("Error reading Snowflake username: %v", err)
 			}
 			if user == "" {
-				fmt.Println("❌ Error: Snowflake username is required when using Snowflake data source")
-				os.Exit(1)
+				fatalf("Error: Snowflake username is required when using Snowflake data source")
 			}
 			finalSnowflakeUser = user
 		}
@@ -2060,12 +8925,10 @@ You can now safely disconnect from SSH. The process will continue running.
 		if finalSnowflakePassword == "" {
 			password, err := promptForPassword("❄️  Enter Snowflake password (or PAT token): ")
 			if err != nil {
-				fmt.Printf("❌ Error reading Snowflake password: %v\n", err)
-				os.Exit(1)
+				fatalf("Error reading Snowflake password: %v", err)
 			}
 			if password == "" {
-				fmt.Println("❌ Error: Snowflake password/token is required when using Snowflake data source")
-				os.Exit(1)
+				fatalf("Error: Snowflake password/token is required when using Snowflake data source")
 			}
 			finalSnowflakePassword = password
 		}
@@ -2081,9 +8944,15 @@ You can now safely disconnect from SSH. The process will continue running.
 	// Determine upsert mode (CLI flag OR config file)
 	finalUpsert := *upsertFlag || fileConfig.Performance.Upsert
 
+	finalManagementURL := overrideString(*managementURL, fileConfig.Skyflow.ManagementURL)
+	if finalManagementURL == "" {
+		finalManagementURL = "https://manage.skyflowapis.com"
+	}
+
 	config := &Config{
 		VaultURL:         overrideString(*vaultURL, fileConfig.Skyflow.VaultURL),
-		BearerToken:      finalBearerToken,
+		ManagementURL:    strings.TrimRight(finalManagementURL, "/"),
+		Auth:             authProvider,
 		BatchSize:        overrideInt(*batchSize, fileConfig.Performance.BatchSize, 0),
 		MaxConcurrency:   overrideInt(*maxConcurrency, fileConfig.Performance.MaxConcurrency, 0),
 		MaxRecords:       finalMaxRecords,
@@ -2112,11 +8981,95 @@ You can now safely disconnect from SSH. The process will continue running.
 		},
 	}
 
+	// Per-vault credentials; vaults sharing credentials share a token provider
+	config.VaultAuth = make(map[string]*TokenProvider)
+	providers := make(map[string]*TokenProvider)
+	for _, v := range fileConfig.Skyflow.Vaults {
+		if !selectedVault(v) || !v.HasCredentials() {
+			continue
+		}
+		key := "token:" + v.BearerToken
+		if v.CredentialsFile != "" {
+			key = "credentials:" + v.CredentialsFile
+		}
+		provider, ok := providers[key]
+		if !ok {
+			if v.CredentialsFile != "" {
+				provider, err = NewServiceAccountTokenProvider(v.CredentialsFile)
+				if err != nil {
+					fatalf("Failed to load service account credentials for vault %s: %v", v.Name, err)
+				}
+				if !*dryRun {
+					if _, err := provider.Token(); err != nil {
+						fatalf("Failed to obtain bearer token for vault %s: %v", v.Name, err)
+					}
+				}
+			} else {
+				provider = NewStaticTokenProvider(v.BearerToken)
+			}
+			providers[key] = provider
+		}
+		config.VaultAuth[v.Name] = provider
+	}
+
+	// Suffix strategy for -append-suffix
+	if config.AppendSuffix {
+		generator, err := NewSuffixGenerator(overrideString(*suffixStrategy, fileConfig.Performance.SuffixStrategy),
+			overrideString(*runTag, fileConfig.Performance.RunTag))
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+		config.Suffix = generator
+		if generator.RunTag != "" {
+			fmt.Printf("🏷️  Suffix strategy: %s (run tag %s)\n", generator.Strategy, generator.RunTag)
+		} else {
+			fmt.Printf("🏷️  Suffix strategy: %s\n", generator.Strategy)
+		}
+	}
+
+	// Request body compression (dry-runs write the uncompressed payload)
+	finalCompression := overrideString(*compressFlag, fileConfig.Performance.RequestCompression)
+	if finalCompression != "" && finalCompression != "none" && !*dryRun {
+		level := overrideInt(*compressLevel, fileConfig.Performance.CompressionLevel, -1)
+		compressor, err := NewRequestCompressor(finalCompression, level)
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+		config.Compressor = compressor
+		levelLabel := "default"
+		if level != 0 {
+			levelLabel = strconv.Itoa(level)
+		}
+		fmt.Printf("🗜️  Request compression: %s (level %s)\n", compressor.Encoding, levelLabel)
+	}
+
+	// Dry-run: payloads go to a file sink instead of the Skyflow API
+	if *dryRun {
+		outputPath := *dryRunOutput
+		if outputPath == "" {
+			outputPath = fmt.Sprintf("dry_run_%s.ndjson", time.Now().Format("20060102_150405"))
+		}
+		sink, err := NewPayloadSink(outputPath, *dryRunGzip, *dryRunRedact)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		atExit(func() {
+			if err := sink.Close(); err != nil {
+				fmt.Printf("⚠️  Failed to close dry-run output: %v\n", err)
+			}
+		})
+		config.DryRun = sink
+		fmt.Printf("🧪 Dry-run mode: payloads will be written to %s (nothing is sent)\n", sink.Path)
+	}
+
 	// Load vaults from config file
 	vaults := fileConfig.Skyflow.Vaults
 	if len(vaults) == 0 {
-		fmt.Printf("❌ Error: No vaults defined in config file\n")
-		os.Exit(1)
+		fatalf("Error: No vaults defined in config file")
+	}
+
+	if err := validateVaultConfigs(vaults, config.Upsert); err != nil {
+		fatalf("Error: Invalid vault configuration: %v", err)
 	}
 
 	// Filter to specific vault if requested
@@ -2129,8 +9082,7 @@ You can now safely disconnect from SSH. The process will continue running.
 			}
 		}
 		if len(filtered) == 0 {
-			fmt.Printf("❌ Error: Unknown vault '%s'\n", *vault)
-			os.Exit(1)
+			fatalf("Error: Unknown vault '%s'", *vault)
 		}
 		vaults = filtered
 		fmt.Printf("🎯 Single-vault mode: Processing %s vault only\n", strings.ToUpper(*vault))
@@ -2147,8 +9099,7 @@ You can now safely disconnect from SSH. The process will continue running.
 			ErrorLogPath: *errorLog,
 		}
 		if err := errorLogSource.Connect(); err != nil {
-			fmt.Printf("❌ Failed to load error log: %v\n", err)
-			os.Exit(1)
+			fatalf("Failed to load error log: %v", err)
 		}
 
 		// Override vault filter to match the error log's vault
@@ -2160,26 +9111,40 @@ You can now safely disconnect from SSH. The process will continue running.
 			}
 		}
 		if len(filtered) == 0 {
-			fmt.Printf("❌ Error: Vault '%s' from error log not found in config\n", errorLogSource.VaultName)
-			os.Exit(1)
+			fatalf("Error: Vault '%s' from error log not found in config", errorLogSource.VaultName)
+		}
+
+		// Replay into the table/column the failed records were originally sent to
+		// (older error logs have no table field - table was always the column then)
+		if errorLogSource.Column != "" && errorLogSource.Column != filtered[0].Column {
+			fmt.Printf("⚠️  Error log column '%s' differs from config column '%s' - using error log value\n",
+				errorLogSource.Column, filtered[0].Column)
+			filtered[0].Column = errorLogSource.Column
+		}
+		if errorLogSource.Table == "" {
+			errorLogSource.Table = errorLogSource.Column
+		}
+		if errorLogSource.Table != filtered[0].TableName() {
+			fmt.Printf("⚠️  Error log table '%s' differs from config table '%s' - using error log value\n",
+				errorLogSource.Table, filtered[0].TableName())
+			filtered[0].Table = errorLogSource.Table
 		}
 		vaults = filtered
 
 		// Display stats and get confirmation
 		proceed, err := displayErrorLogStats(errorLogSource, config)
 		if err != nil {
-			fmt.Printf("❌ Error getting confirmation: %v\n", err)
-			os.Exit(1)
+			fatalf("Error getting confirmation: %v", err)
 		}
 		if !proceed {
 			fmt.Printf("\n❌ Operation cancelled by user.\n")
-			os.Exit(0)
+			return
 		}
 
 		fmt.Printf("\n✅ Proceeding with reprocessing...\n")
 
 		ds = errorLogSource
-		defer ds.Close()
+		atExit(func() { ds.Close() })
 	} else if config.DataSource == "snowflake" {
 		fmt.Printf("❄️  Using Snowflake data source\n")
 		fmt.Printf("   User: %s\n", config.SnowflakeConfig.User)
@@ -2191,11 +9156,10 @@ You can now safely disconnect from SSH. The process will continue running.
 			Config: config.SnowflakeConfig,
 		}
 		if err := sfSource.Connect(); err != nil {
-			fmt.Printf("❌ Failed to connect to Snowflake: %v\n", err)
-			os.Exit(1)
+			fatalf("Failed to connect to Snowflake: %v", err)
 		}
 		ds = sfSource
-		defer ds.Close()
+		atExit(func() { ds.Close() })
 	} else {
 		fmt.Printf("📁 Using CSV data source\n")
 		fmt.Printf("   Data directory: %s\n", config.DataDirectory)
@@ -2204,18 +9168,238 @@ You can now safely disconnect from SSH. The process will continue running.
 			DataDirectory: config.DataDirectory,
 		}
 		if err := csvSource.Connect(); err != nil {
-			fmt.Printf("❌ Failed to validate data directory: %v\n", err)
-			os.Exit(1)
+			fatalf("Failed to validate data directory: %v", err)
 		}
 		ds = csvSource
-		defer ds.Close()
+		atExit(func() { ds.Close() })
+	}
+
+	// skyflow_id crosswalk sink (file, or a Snowflake table over the source connection when possible)
+	if (*crosswalkFile != "" || *crosswalkTable != "") && config.DryRun != nil {
+		fmt.Printf("⚠️  Ignoring crosswalk in dry-run mode (no skyflow_ids are created)\n")
+	} else if *crosswalkFile != "" && *crosswalkTable != "" {
+		fatalf("Error: use either -crosswalk or -crosswalk-table, not both")
+	} else if *crosswalkFile != "" {
+		key, err := crosswalkKey(fileConfig.Skyflow.CrosswalkKey)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		sink, err := NewFileCrosswalkSink(*crosswalkFile, *crosswalkFormat, key)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		atExit(func() {
+			if err := sink.Close(); err != nil {
+				fmt.Printf("⚠️  Failed to close crosswalk file: %v\n", err)
+			}
+		})
+		config.Crosswalk = sink
+		fmt.Printf("🔗 Writing skyflow_id crosswalk to %s (%s)\n", sink.Target, sink.Format)
+	} else if *crosswalkTable != "" {
+		key, err := crosswalkKey(fileConfig.Skyflow.CrosswalkKey)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		sfSource, ok := ds.(*SnowflakeDataSource)
+		if !ok {
+			sfSource = &SnowflakeDataSource{Config: config.SnowflakeConfig}
+			if err := sfSource.Connect(); err != nil {
+				fatalf("Failed to connect to Snowflake for the crosswalk table: %v", err)
+			}
+			atExit(func() { sfSource.Close() })
+		}
+		sink, err := NewSnowflakeCrosswalkSink(sfSource.DB, *crosswalkTable, key)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		atExit(func() {
+			if err := sink.Close(); err != nil {
+				fmt.Printf("⚠️  Failed to write crosswalk table: %v\n", err)
+			}
+		})
+		config.Crosswalk = sink
+		fmt.Printf("🔗 Writing skyflow_id crosswalk to Snowflake table %s\n", sink.Target)
+	}
+
+	// Within-run deduplication (verify/reconcile filter the same way through Filter, which writes no report)
+	if mode := overrideString(*dedupMode, fileConfig.Performance.Dedup); mode != "" && mode != "off" {
+		policy := overrideString(*dedupPolicy, fileConfig.Performance.DedupPolicy)
+		if policy == "" {
+			policy = "keep-first"
+		}
+		reportPath := *conflictReport
+		if reportPath == "" {
+			reportPath = fmt.Sprintf("conflict_report_%s.json", time.Now().Format("20060102_150405"))
+		}
+		if *verifyMode || *reconcileMode {
+			reportPath = ""
+		}
+		dedup, err := NewDeduplicator(mode, policy, *dedupDir, reportPath)
+		if err != nil {
+			fatalf("Error: %v", err)
+		}
+		config.Dedup = dedup
+		fmt.Printf("🧹 Deduplication: %s (conflict policy %s)\n", dedup.Mode, dedup.Policy)
+	}
+
+	// Reject file for records failing validation rules (created on the first reject)
+	if !*verifyMode && !*reconcileMode {
+		rejectPath := *rejectFile
+		if rejectPath == "" {
+			rejectPath = fmt.Sprintf("rejects_%s.ndjson", time.Now().Format("20060102_150405"))
+		}
+		config.Rejects = &RejectSink{Path: rejectPath}
+		atExit(func() {
+			if err := config.Rejects.Close(); err != nil {
+				fmt.Printf("⚠️  Failed to close reject file: %v\n", err)
+			}
+		})
+	}
+
+	runMode := "load"
+	switch {
+	case *verifyMode:
+		runMode = "verify"
+	case *reconcileMode:
+		runMode = "reconcile"
+	case *errorLog != "":
+		runMode = "error-log"
+	case config.DryRun != nil:
+		runMode = "dry-run"
+	}
+
+	// Snapshot the effective configuration for the run report
+	if runReport != nil {
+		runReport.Mode = runMode
+		runReport.SetConfig(*configFile, finalCredentialsFile, *errorLog, config, networkConfig, vaults)
+		runReport.SetSinks(config)
+	}
+
+	// OpenTelemetry spans for the run, vaults, batches and HTTP attempts (covers pre-flight and verify too)
+	if *traceOTLP != "" || *traceFile != "" {
+		t, err := NewTracer(*traceOTLP, *traceFile, *traceSample)
+		if err != nil {
+			fatalf("Failed to set up tracing: %v", err)
+		}
+		tracer = t
+		defer tracer.Shutdown("")
+		tracer.StartRun(runMode, attribute.String("source.type", config.DataSource), attribute.Int("vault.count", len(vaults)))
+		if runReport != nil {
+			runReport.Artifacts.TraceFile = *traceFile
+		}
+		fmt.Printf("🔭 Tracing %.0f%% of batches\n", *traceSample*100)
+	}
+
+	// Webhook notifications (start now, progress while loading, completion or fatal error at the end)
+	notifications := fileConfig.Notifications
+	if *webhookURL != "" {
+		notifications.Webhooks = append(notifications.Webhooks, WebhookConfig{URL: *webhookURL, Format: *webhookFormat})
+	}
+	if *webhookProgress > 0 {
+		notifications.ProgressIntervalMinutes = *webhookProgress
+	}
+	if len(notifications.Webhooks) > 0 {
+		n, err := NewNotifier(notifications)
+		if err != nil {
+			fatalf("Invalid notifications config: %v", err)
+		}
+		notifier = n
+		fmt.Printf("🔔 Webhooks: %d configured, progress every %s\n", len(n.targets), n.interval)
+		notifier.Start(runMode, runReport)
+	}
+
+	// Validate vault schemas and token formats before touching any data
+	if *preflight {
+		if !runPreflight(config, vaults, ds, *preflightSample) {
+			exitRun(exitFatal, "pre-flight checks failed")
+		}
+	}
+
+	// Verify mode: reconcile the vault against the source instead of loading
+	if *verifyMode {
+		if config.DryRun != nil {
+			fatalf("Error: -verify and -dry-run cannot be combined (verification calls the vault API)")
+		}
+		if config.AppendSuffix && !config.Suffix.Reproducible() {
+			fatalf("Error: -verify cannot check loads made with random suffixes (use -suffix-strategy deterministic or run-tag with the load's -run-tag)")
+		}
+		reportPath := *verifyReport
+		if reportPath == "" {
+			reportPath = fmt.Sprintf("verify_report_%s.json", time.Now().Format("20060102_150405"))
+		}
+		if runReport != nil {
+			runReport.Artifacts.VerifyReport = reportPath
+		}
+		if !runVerification(config, vaults, ds, *verifySample, reportPath) {
+			exitRun(exitFatal, "verification failed (see "+reportPath+")")
+		}
+		finishRun(exitSuccess, "")
+		return
+	}
+
+	// Reconcile mode: compare source and vault record counts instead of loading
+	if *reconcileMode {
+		if config.DryRun != nil {
+			fatalf("Error: -reconcile and -dry-run cannot be combined (reconciliation calls the vault API)")
+		}
+		reportPath := *reconcileReport
+		if reportPath == "" {
+			reportPath = fmt.Sprintf("reconcile_report_%s.json", time.Now().Format("20060102_150405"))
+		}
+		if runReport != nil {
+			runReport.Artifacts.ReconcileReport = reportPath
+		}
+		if !runReconciliation(config, vaults, ds, reportPath) {
+			exitRun(exitFatal, "reconciliation failed (see "+reportPath+")")
+		}
+		finishRun(exitSuccess, "")
+		return
+	}
+
+	// Prometheus endpoint (bound before -clear so a busy port fails before anything is deleted)
+	if *metricsAddr != "" {
+		exporter := NewMetricsExporter(*metricsAddr)
+		if err := exporter.Start(); err != nil {
+			fatalf("Failed to start metrics endpoint: %v", err)
+		}
+		atExit(exporter.Close)
+		config.Exporter = exporter
+		fmt.Printf("📡 Prometheus metrics: http://%s/metrics\n", exporter.Addr)
+	}
+
+	// Runtime control API (ctl finds the socket through the PID file, which offline mode already writes)
+	if *controlSocket || *controlAddr != "" {
+		socketPath := ""
+		if *controlAddr == "" {
+			socketPath = controlSocketPath(os.Getpid())
+		}
+		controller = NewLoadController()
+		if err := controller.Start(socketPath, *controlAddr); err != nil {
+			fatalf("Failed to start control API: %v", err)
+		}
+		atExit(controller.Close)
+		if !*offlineMode {
+			if err := createPIDFile(); err != nil {
+				fmt.Printf("⚠️  Warning: Failed to create PID file: %v\n", err)
+			} else {
+				atExit(removePIDFile)
+			}
+		}
+		if *controlAddr != "" {
+			fmt.Printf("🎛️  Control API: %s, token in %s (%s ctl -addr %s pause|resume|set|state)\n",
+				controller.Addr, controlTokenPath(os.Getpid()), os.Args[0], controller.Addr)
+		} else {
+			fmt.Printf("🎛️  Control API: %s (%s ctl pause|resume|set|state)\n", controller.Addr, os.Args[0])
+		}
 	}
 
-	// Clear vaults if requested
-	if *clearVaults {
+	// Clear vaults if requested (never in dry-run - nothing may touch the vault)
+	if *clearVaults && config.DryRun != nil {
+		fmt.Printf("⚠️  Ignoring -clear in dry-run mode\n")
+	} else if *clearVaults {
 		if err := clearAllVaults(config, vaults); err != nil {
 			fmt.Printf("\n❌ Failed to clear vaults: %v\n", err)
-			os.Exit(1)
+			exitRun(exitFatal, fmt.Sprintf("failed to clear vaults: %v", err))
 		}
 		fmt.Printf("\nProceeding with data load...\n")
 	}
@@ -2226,10 +9410,26 @@ You can now safely disconnect from SSH. The process will continue running.
 	var allMetrics []*Metrics
 
 	for _, v := range vaults {
-		metrics := processVault(config, v, ds)
+		metrics := processVault(config.ForVault(v), v, ds)
+		config.Exporter.Finish(metrics)
 		allMetrics = append(allMetrics, metrics)
+		if metrics.Aborted {
+			fmt.Printf("\n❌ Stopping: %s was not loaded and remaining vaults are skipped\n", v.Name)
+			break
+		}
+	}
+
+	// Write out queued crosswalk entries so the summary and report count them
+	if err := config.Crosswalk.Close(); err != nil {
+		fmt.Printf("⚠️  Failed to write crosswalk: %v\n", err)
 	}
 
 	// Display summary
-	displaySummary(allMetrics, totalStart)
+	displaySummary(allMetrics, totalStart, config)
+
+	// Exit status: 0 = every vault fully loaded, 3 = failed batches or vaults, 1 = a vault was aborted
+	runReport.AddVaults(allMetrics, vaults, config, time.Since(totalStart))
+	code, reason := loadOutcome(allMetrics)
+	finishRun(code, reason)
+	exitCode = code
 }
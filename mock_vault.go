@@ -0,0 +1,867 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Configuration structures (reusing same config.json format as main.go)
+type FileConfig struct {
+	Skyflow SkyflowConfig `json:"skyflow"`
+}
+
+type SkyflowConfig struct {
+	Vaults []VaultConfig `json:"vaults"`
+}
+
+type VaultConfig struct {
+	Name    string          `json:"name"`
+	ID      string          `json:"id"`
+	Table   string          `json:"table"`
+	Column  string          `json:"column"`
+	Columns []ColumnMapping `json:"columns,omitempty"`
+}
+
+type ColumnMapping struct {
+	Column string `json:"column"`
+}
+
+// TableName returns the vault table, falling back to the column name
+func (v VaultConfig) TableName() string {
+	if v.Table != "" {
+		return v.Table
+	}
+	return v.Column
+}
+
+// Request/response structures (subset of the Skyflow data plane API)
+type InsertRequest struct {
+	Records []struct {
+		Table  string            `json:"table"` // Only used by POST /v1/vaults/{id}
+		Fields map[string]string `json:"fields"`
+		Tokens map[string]string `json:"tokens"`
+	} `json:"records"`
+	Tokenization bool   `json:"tokenization"`
+	Upsert       string `json:"upsert"`
+}
+
+type InsertResponseRecord struct {
+	SkyflowID    string            `json:"skyflow_id,omitempty"`
+	Tokens       map[string]string `json:"tokens,omitempty"`
+	RequestIndex int               `json:"request_index"`
+	Error        *APIError         `json:"error,omitempty"`
+}
+
+type DetokenizeRequest struct {
+	DetokenizationParameters []struct {
+		Token     string `json:"token"`
+		Redaction string `json:"redaction"`
+	} `json:"detokenizationParameters"`
+}
+
+type DetokenizeResponseRecord struct {
+	Token     string    `json:"token"`
+	ValueType string    `json:"valueType,omitempty"`
+	Value     string    `json:"value,omitempty"`
+	Error     *APIError `json:"error,omitempty"`
+}
+
+type DeletePayload struct {
+	SkyflowIDs []string `json:"skyflow_ids"`
+}
+
+type APIError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// mockRecord is a single row stored in a mock vault table
+type mockRecord struct {
+	id     string
+	fields map[string]string
+	tokens map[string]string
+}
+
+// mockTable keeps rows in insertion order so offset/limit listing is stable, plus a
+// column -> value -> skyflow_id index for upserts
+type mockTable struct {
+	records map[string]*mockRecord
+	order   []string
+	deleted int
+	index   map[string]map[string]string
+}
+
+// mockVault holds the tables of one vault plus its token -> value map (shared across tables,
+// as detokenize is addressed per vault)
+type mockVault struct {
+	tables map[string]*mockTable
+	tokens map[string]string
+}
+
+// MockStore is the in-memory vault store
+type MockStore struct {
+	mu     sync.RWMutex
+	vaults map[string]*mockVault
+	nextID int64
+}
+
+func NewMockStore() *MockStore {
+	return &MockStore{vaults: make(map[string]*mockVault)}
+}
+
+func (s *MockStore) vault(vaultID string) *mockVault {
+	v, ok := s.vaults[vaultID]
+	if !ok {
+		v = &mockVault{tables: make(map[string]*mockTable), tokens: make(map[string]string)}
+		s.vaults[vaultID] = v
+	}
+	return v
+}
+
+func (v *mockVault) table(name string) *mockTable {
+	t, ok := v.tables[name]
+	if !ok {
+		t = &mockTable{records: make(map[string]*mockRecord), index: make(map[string]map[string]string)}
+		v.tables[name] = t
+	}
+	return t
+}
+
+// setField stores a column value and keeps the upsert index in sync
+func (t *mockTable) setField(rec *mockRecord, column, value, token string) {
+	if old, ok := rec.fields[column]; ok && t.index[column][old] == rec.id {
+		delete(t.index[column], old)
+	}
+	rec.fields[column] = value
+	rec.tokens[column] = token
+	if t.index[column] == nil {
+		t.index[column] = make(map[string]string)
+	}
+	t.index[column][value] = rec.id
+}
+
+// remove deletes a record and its index entries
+func (t *mockTable) remove(id string) bool {
+	rec, ok := t.records[id]
+	if !ok {
+		return false
+	}
+	for column, value := range rec.fields {
+		if t.index[column][value] == id {
+			delete(t.index[column], value)
+		}
+	}
+	delete(t.records, id)
+	t.deleted++
+	return true
+}
+
+// compact drops deleted IDs from the insertion order once they dominate it
+func (t *mockTable) compact() {
+	if t.deleted < len(t.order)/2 {
+		return
+	}
+	live := t.order[:0]
+	for _, id := range t.order {
+		if _, ok := t.records[id]; ok {
+			live = append(live, id)
+		}
+	}
+	t.order = live
+	t.deleted = 0
+}
+
+// generateToken derives a stable UUID-shaped token for a value, so repeated tokenization
+// of the same value returns the same token (like a deterministic token table)
+func generateToken(vaultID, column, value string) string {
+	sum := sha256.Sum256([]byte(vaultID + "\x00" + column + "\x00" + value))
+	h := hex.EncodeToString(sum[:16])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// Latency distribution for simulated API response times
+type LatencyModel struct {
+	spec   string
+	sample func(r *rand.Rand) time.Duration
+}
+
+// parseLatency parses "0", "fixed:50ms", "uniform:20ms-200ms", "normal:80ms,20ms" or "exp:50ms"
+func parseLatency(spec string) (*LatencyModel, error) {
+	kind, args, _ := strings.Cut(spec, ":")
+	model := &LatencyModel{spec: spec}
+
+	switch kind {
+	case "", "0", "none":
+		model.sample = func(*rand.Rand) time.Duration { return 0 }
+	case "fixed":
+		d, err := time.ParseDuration(args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixed latency %q: %w", args, err)
+		}
+		model.sample = func(*rand.Rand) time.Duration { return d }
+	case "uniform":
+		lo, hi, ok := strings.Cut(args, "-")
+		if !ok {
+			return nil, fmt.Errorf("uniform latency must be min-max, got %q", args)
+		}
+		minD, err := time.ParseDuration(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uniform min %q: %w", lo, err)
+		}
+		maxD, err := time.ParseDuration(hi)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uniform max %q: %w", hi, err)
+		}
+		if maxD < minD {
+			return nil, fmt.Errorf("uniform max %s is below min %s", maxD, minD)
+		}
+		model.sample = func(r *rand.Rand) time.Duration {
+			return minD + time.Duration(r.Int63n(int64(maxD-minD)+1))
+		}
+	case "normal":
+		m, sd, ok := strings.Cut(args, ",")
+		if !ok {
+			return nil, fmt.Errorf("normal latency must be mean,stddev, got %q", args)
+		}
+		mean, err := time.ParseDuration(m)
+		if err != nil {
+			return nil, fmt.Errorf("invalid normal mean %q: %w", m, err)
+		}
+		stddev, err := time.ParseDuration(sd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid normal stddev %q: %w", sd, err)
+		}
+		model.sample = func(r *rand.Rand) time.Duration {
+			d := time.Duration(r.NormFloat64()*float64(stddev)) + mean
+			if d < 0 {
+				return 0
+			}
+			return d
+		}
+	case "exp":
+		mean, err := time.ParseDuration(args)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponential mean %q: %w", args, err)
+		}
+		model.sample = func(r *rand.Rand) time.Duration {
+			return time.Duration(r.ExpFloat64() * float64(mean))
+		}
+	default:
+		return nil, fmt.Errorf("unknown latency distribution %q (use fixed, uniform, normal or exp)", kind)
+	}
+	return model, nil
+}
+
+// Fault injection settings
+type FaultConfig struct {
+	Rate429         float64
+	Rate5xx         float64
+	RecordErrorRate float64
+	RetryAfter      int // Seconds advertised in Retry-After on 429 (0 = header omitted)
+}
+
+// Server counters, reported periodically and on shutdown
+type ServerStats struct {
+	Requests        int64
+	Inserted        int64
+	Upserted        int64
+	Deleted         int64
+	Detokenized     int64
+	Injected429     int64
+	Injected5xx     int64
+	RecordErrors    int64
+	TokenConflicts  int64
+	Unauthenticated int64
+	CompressedReqs  int64
+	RequestBytes    int64 // Request body bytes as received (compressed or not)
+}
+
+// MockServer serves the vault, management and token endpoints
+type MockServer struct {
+	store     *MockStore
+	latency   *LatencyModel
+	faults    FaultConfig
+	tokenType string
+	encodings map[string]bool         // Accepted request Content-Encodings
+	schemas   map[string]*VaultConfig // Configured vaults by ID (for the management schema)
+	stats     ServerStats
+	randPool  sync.Pool
+}
+
+func NewMockServer(latency *LatencyModel, faults FaultConfig, tokenType string, encodings []string, vaults []VaultConfig) *MockServer {
+	s := &MockServer{
+		store:     NewMockStore(),
+		latency:   latency,
+		faults:    faults,
+		tokenType: tokenType,
+		encodings: make(map[string]bool),
+		schemas:   make(map[string]*VaultConfig),
+	}
+	for _, encoding := range encodings {
+		s.encodings[encoding] = true
+	}
+	s.randPool.New = func() interface{} {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	for i := range vaults {
+		s.schemas[vaults[i].ID] = &vaults[i]
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"grpc_code":   status,
+			"http_code":   status,
+			"http_status": http.StatusText(status),
+			"message":     message,
+		},
+	})
+}
+
+// ServeHTTP applies latency, auth and fault injection, then routes the request
+func (s *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.stats.Requests, 1)
+
+	rng := s.randPool.Get().(*rand.Rand)
+	delay := s.latency.sample(rng)
+	roll429, roll5xx := rng.Float64(), rng.Float64()
+	s.randPool.Put(rng)
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	// Token endpoint is unauthenticated (the assertion is the credential)
+	if r.URL.Path == "/v1/auth/sa/oauth/token" && r.Method == "POST" {
+		s.handleToken(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		atomic.AddInt64(&s.stats.Unauthenticated, 1)
+		writeError(w, http.StatusUnauthorized, "Unauthenticated. Missing bearer token")
+		return
+	}
+
+	if roll429 < s.faults.Rate429 {
+		atomic.AddInt64(&s.stats.Injected429, 1)
+		if s.faults.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(s.faults.RetryAfter))
+		}
+		writeError(w, http.StatusTooManyRequests, "Too many requests (injected)")
+		return
+	}
+	if roll5xx < s.faults.Rate5xx {
+		atomic.AddInt64(&s.stats.Injected5xx, 1)
+		writeError(w, http.StatusServiceUnavailable, "Service unavailable (injected)")
+		return
+	}
+
+	// Compressed request bodies (gzip/zstd), rejected with 415 unless accepted via -accept-encoding
+	if encoding := r.Header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		if !s.encodings[encoding] {
+			writeError(w, http.StatusUnsupportedMediaType, "Unsupported Content-Encoding: "+encoding)
+			return
+		}
+		body, err := decodeBody(r.Body, encoding)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid %s request body: %v", encoding, err))
+			return
+		}
+		atomic.AddInt64(&s.stats.CompressedReqs, 1)
+		r.Body = body
+	}
+	if r.Body != nil && r.ContentLength > 0 {
+		atomic.AddInt64(&s.stats.RequestBytes, r.ContentLength)
+	}
+
+	// Routes: /v1/vaults/{id}, /v1/vaults/{id}/detokenize, /v1/vaults/{id}/{table}[/{skyflow_id}]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "v1" || parts[1] != "vaults" {
+		writeError(w, http.StatusNotFound, "Not found: "+r.URL.Path)
+		return
+	}
+	vaultID := parts[2]
+
+	switch {
+	case len(parts) == 3 && r.Method == "GET":
+		s.handleSchema(w, vaultID)
+	case len(parts) == 3 && r.Method == "POST":
+		s.handleInsert(w, r, vaultID, "")
+	case len(parts) == 4 && parts[3] == "detokenize" && r.Method == "POST":
+		s.handleDetokenize(w, r, vaultID)
+	case len(parts) == 4 && r.Method == "POST":
+		s.handleInsert(w, r, vaultID, parts[3])
+	case len(parts) == 4 && r.Method == "GET":
+		s.handleList(w, r, vaultID, parts[3])
+	case len(parts) == 4 && r.Method == "DELETE":
+		s.handleDelete(w, r, vaultID, parts[3])
+	case len(parts) == 5 && r.Method == "GET":
+		s.handleGet(w, vaultID, parts[3], parts[4])
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s %s is not supported by the mock vault", r.Method, r.URL.Path))
+	}
+}
+
+// decodeBody wraps a compressed request body in the matching decompressor
+func decodeBody(body io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch encoding {
+	case "gzip":
+		return gzip.NewReader(body)
+	case "zstd":
+		decoder, err := zstd.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported encoding %s", encoding)
+}
+
+// handleToken accepts any signed assertion and returns a JWT-shaped bearer token with an exp claim
+func (s *MockServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		GrantType string `json:"grant_type"`
+		Assertion string `json:"assertion"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Assertion == "" {
+		writeError(w, http.StatusBadRequest, "Missing assertion")
+		return
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": "mock-vault",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	accessToken := header + "." + base64.RawURLEncoding.EncodeToString(claims) + ".mock"
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"accessToken": accessToken,
+		"tokenType":   "Bearer",
+	})
+}
+
+// handleSchema returns a management-API style schema for configured vaults (used by -preflight)
+func (s *MockServer) handleSchema(w http.ResponseWriter, vaultID string) {
+	vc, ok := s.schemas[vaultID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Vault not found: "+vaultID)
+		return
+	}
+
+	columns := []string{vc.Column}
+	if len(vc.Columns) > 0 {
+		columns = columns[:0]
+		for _, m := range vc.Columns {
+			columns = append(columns, m.Column)
+		}
+	}
+
+	fields := []map[string]interface{}{{"name": "skyflow_id", "datatype": "DT_STRING"}}
+	for _, column := range columns {
+		fields = append(fields, map[string]interface{}{
+			"name":     column,
+			"datatype": "DT_STRING",
+			"properties": map[string]interface{}{
+				"tokenTableConfig": map[string]interface{}{
+					"tokenType": map[string]string{"type": s.tokenType},
+				},
+			},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"vault": map[string]interface{}{
+			"ID":   vc.ID,
+			"name": vc.Name,
+			"schemas": []map[string]interface{}{
+				{"name": vc.TableName(), "fields": fields},
+			},
+		},
+	})
+}
+
+// handleInsert stores records (BYOT tokens if supplied, generated tokens otherwise).
+// A token already bound to a different value, or an injected fault, fails just that record.
+func (s *MockServer) handleInsert(w http.ResponseWriter, r *http.Request, vaultID, tableName string) {
+	var req InsertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	rng := s.randPool.Get().(*rand.Rand)
+	defer s.randPool.Put(rng)
+
+	results := make([]InsertResponseRecord, len(req.Records))
+
+	s.store.mu.Lock()
+	vault := s.store.vault(vaultID)
+
+	for i, rec := range req.Records {
+		results[i].RequestIndex = i
+
+		if rng.Float64() < s.faults.RecordErrorRate {
+			atomic.AddInt64(&s.stats.RecordErrors, 1)
+			results[i].Error = &APIError{Code: "500", Message: "Record insert failed (injected)"}
+			continue
+		}
+
+		table := tableName
+		if table == "" {
+			table = rec.Table
+		}
+		if table == "" || len(rec.Fields) == 0 {
+			results[i].Error = &APIError{Code: "400", Message: "Record is missing table or fields"}
+			continue
+		}
+		t := vault.table(table)
+
+		// Resolve tokens first so a conflict leaves the store untouched
+		tokens := make(map[string]string, len(rec.Fields))
+		var conflict string
+		for column, value := range rec.Fields {
+			token := rec.Tokens[column]
+			if token == "" {
+				token = generateToken(vaultID, column, value)
+			}
+			if existing, ok := vault.tokens[token]; ok && existing != value {
+				conflict = column
+				break
+			}
+			tokens[column] = token
+		}
+		if conflict != "" {
+			atomic.AddInt64(&s.stats.TokenConflicts, 1)
+			results[i].Error = &APIError{Code: "409", Message: fmt.Sprintf("Token for column %s is already in use for a different value", conflict)}
+			continue
+		}
+
+		// Upsert: update the existing row whose upsert column has the same value
+		var target *mockRecord
+		if req.Upsert != "" {
+			if id, ok := t.index[req.Upsert][rec.Fields[req.Upsert]]; ok {
+				target = t.records[id]
+			}
+		}
+		if target != nil {
+			for column, value := range rec.Fields {
+				t.setField(target, column, value, tokens[column])
+			}
+			atomic.AddInt64(&s.stats.Upserted, 1)
+		} else {
+			s.store.nextID++
+			target = &mockRecord{
+				id:     fmt.Sprintf("%08x-0000-4000-8000-%012x", s.store.nextID>>48, s.store.nextID&0xffffffffffff),
+				fields: make(map[string]string, len(rec.Fields)),
+				tokens: make(map[string]string, len(rec.Fields)),
+			}
+			for column, value := range rec.Fields {
+				t.setField(target, column, value, tokens[column])
+			}
+			t.records[target.id] = target
+			t.order = append(t.order, target.id)
+			atomic.AddInt64(&s.stats.Inserted, 1)
+		}
+		for column, token := range tokens {
+			vault.tokens[token] = rec.Fields[column]
+		}
+
+		results[i].SkyflowID = target.id
+		if req.Tokenization {
+			results[i].Tokens = tokens
+		}
+	}
+	s.store.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"records": results})
+}
+
+// handleList returns skyflow_ids (and fields) using offset/limit paging
+func (s *MockServer) handleList(w http.ResponseWriter, r *http.Request, vaultID, tableName string) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 25
+	}
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	records := make([]map[string]interface{}, 0, limit)
+	if vault, ok := s.store.vaults[vaultID]; ok {
+		if t, ok := vault.tables[tableName]; ok {
+			skipped := 0
+			for _, id := range t.order {
+				rec, ok := t.records[id]
+				if !ok {
+					continue
+				}
+				if skipped < offset {
+					skipped++
+					continue
+				}
+				records = append(records, map[string]interface{}{"fields": recordFields(rec)})
+				if len(records) >= limit {
+					break
+				}
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"records": records})
+}
+
+// handleGet returns a single record by skyflow_id
+func (s *MockServer) handleGet(w http.ResponseWriter, vaultID, tableName, skyflowID string) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	if vault, ok := s.store.vaults[vaultID]; ok {
+		if t, ok := vault.tables[tableName]; ok {
+			if rec, ok := t.records[skyflowID]; ok {
+				writeJSON(w, http.StatusOK, map[string]interface{}{"fields": recordFields(rec)})
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "No Records Found")
+}
+
+func recordFields(rec *mockRecord) map[string]string {
+	fields := make(map[string]string, len(rec.fields)+1)
+	for column, value := range rec.fields {
+		fields[column] = value
+	}
+	fields["skyflow_id"] = rec.id
+	return fields
+}
+
+// handleDelete removes records by skyflow_id (tokens stay bound, as in a real vault)
+func (s *MockServer) handleDelete(w http.ResponseWriter, r *http.Request, vaultID, tableName string) {
+	var req DeletePayload
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	s.store.mu.Lock()
+	deleted := make([]map[string]interface{}, 0, len(req.SkyflowIDs))
+	if vault, ok := s.store.vaults[vaultID]; ok {
+		if t, ok := vault.tables[tableName]; ok {
+			for _, id := range req.SkyflowIDs {
+				if t.remove(id) {
+					deleted = append(deleted, map[string]interface{}{"skyflow_id": id, "deleted": true})
+				}
+			}
+			t.compact()
+		}
+	}
+	s.store.mu.Unlock()
+
+	atomic.AddInt64(&s.stats.Deleted, int64(len(deleted)))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"RecordIDResponse": deleted})
+}
+
+// handleDetokenize resolves tokens to their stored values
+func (s *MockServer) handleDetokenize(w http.ResponseWriter, r *http.Request, vaultID string) {
+	var req DetokenizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	results := make([]DetokenizeResponseRecord, len(req.DetokenizationParameters))
+
+	s.store.mu.RLock()
+	vault := s.store.vaults[vaultID]
+	for i, param := range req.DetokenizationParameters {
+		results[i].Token = param.Token
+		value, ok := "", false
+		if vault != nil {
+			value, ok = vault.tokens[param.Token]
+		}
+		if !ok {
+			results[i].Error = &APIError{Code: "404", Message: "Token not found"}
+			continue
+		}
+		results[i].ValueType = "STRING"
+		results[i].Value = value
+	}
+	s.store.mu.RUnlock()
+
+	atomic.AddInt64(&s.stats.Detokenized, int64(len(results)))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"records": results})
+}
+
+// recordCounts returns the live record count per vault/table
+func (s *MockServer) recordCounts() map[string]int {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for vaultID, vault := range s.store.vaults {
+		for name, t := range vault.tables {
+			counts[vaultID+"/"+name] = len(t.records)
+		}
+	}
+	return counts
+}
+
+func (s *MockServer) printStats(header string) {
+	fmt.Printf("\n%s\n", header)
+	fmt.Printf("  Requests: %d | Inserted: %d | Upserted: %d | Deleted: %d | Detokenized: %d\n",
+		atomic.LoadInt64(&s.stats.Requests), atomic.LoadInt64(&s.stats.Inserted),
+		atomic.LoadInt64(&s.stats.Upserted), atomic.LoadInt64(&s.stats.Deleted),
+		atomic.LoadInt64(&s.stats.Detokenized))
+	fmt.Printf("  Injected 429s: %d | Injected 5xx: %d | Record errors: %d | Token conflicts: %d | Unauthenticated: %d\n",
+		atomic.LoadInt64(&s.stats.Injected429), atomic.LoadInt64(&s.stats.Injected5xx),
+		atomic.LoadInt64(&s.stats.RecordErrors), atomic.LoadInt64(&s.stats.TokenConflicts),
+		atomic.LoadInt64(&s.stats.Unauthenticated))
+	fmt.Printf("  Request bytes received: %d | Compressed requests: %d\n",
+		atomic.LoadInt64(&s.stats.RequestBytes), atomic.LoadInt64(&s.stats.CompressedReqs))
+
+	counts := s.recordCounts()
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  📦 %s: %d records\n", k, counts[k])
+	}
+}
+
+func loadConfigFile(filepath string) (*FileConfig, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config FileConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	return &config, nil
+}
+
+func validateRate(name string, rate float64) {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		fmt.Printf("❌ Error: -%s must be between 0 and 1 (got %v)\n", name, rate)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "Listen address")
+	configPath := flag.String("config", "", "Path to config.json (optional; serves schemas for the configured vaults)")
+	latencySpec := flag.String("latency", "0", "Latency distribution: 0, fixed:50ms, uniform:20ms-200ms, normal:80ms,20ms or exp:50ms")
+	rate429 := flag.Float64("rate-429", 0, "Fraction of requests rejected with 429 (0-1)")
+	rate5xx := flag.Float64("rate-5xx", 0, "Fraction of requests rejected with 503 (0-1)")
+	recordErrorRate := flag.Float64("record-error-rate", 0, "Fraction of inserted records that fail individually (0-1)")
+	retryAfter := flag.Int("retry-after", 1, "Retry-After seconds sent with injected 429s (0 = omit header)")
+	tokenType := flag.String("token-type", "DETERMINISTIC_UUID", "Token type reported in vault schemas")
+	acceptEncoding := flag.String("accept-encoding", "gzip,zstd", "Accepted request Content-Encodings (empty = reject compressed bodies with 415)")
+	statsInterval := flag.Duration("stats-interval", 10*time.Second, "How often to print server stats (0 = only on shutdown)")
+	flag.Parse()
+
+	validateRate("rate-429", *rate429)
+	validateRate("rate-5xx", *rate5xx)
+	validateRate("record-error-rate", *recordErrorRate)
+
+	latency, err := parseLatency(*latencySpec)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var vaults []VaultConfig
+	if *configPath != "" {
+		fileConfig, err := loadConfigFile(*configPath)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		vaults = fileConfig.Skyflow.Vaults
+	}
+
+	var encodings []string
+	if *acceptEncoding != "" {
+		encodings = strings.Split(*acceptEncoding, ",")
+	}
+
+	server := NewMockServer(latency, FaultConfig{
+		Rate429:         *rate429,
+		Rate5xx:         *rate5xx,
+		RecordErrorRate: *recordErrorRate,
+		RetryAfter:      *retryAfter,
+	}, *tokenType, encodings, vaults)
+
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Println("SKYFLOW MOCK VAULT")
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("Listening:         http://%s\n", *addr)
+	fmt.Printf("Latency:           %s\n", latency.spec)
+	fmt.Printf("429 rate:          %.2f%% (Retry-After: %ds)\n", *rate429*100, *retryAfter)
+	fmt.Printf("5xx rate:          %.2f%%\n", *rate5xx*100)
+	fmt.Printf("Record error rate: %.2f%%\n", *recordErrorRate*100)
+	fmt.Printf("Content-Encoding:  %s\n", strings.Join(append([]string{"identity"}, encodings...), ", "))
+	if len(vaults) > 0 {
+		fmt.Printf("Schemas:           %d vault(s) from %s (token type %s)\n", len(vaults), *configPath, *tokenType)
+	}
+	fmt.Println(strings.Repeat("=", 80))
+
+	if *statsInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(*statsInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				server.printStats(fmt.Sprintf("[STATS] %s", time.Now().Format("15:04:05")))
+			}
+		}()
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		server.printStats("🛑 Shutting down mock vault")
+		os.Exit(0)
+	}()
+
+	httpServer := &http.Server{Addr: *addr, Handler: server}
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		fmt.Printf("❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+}
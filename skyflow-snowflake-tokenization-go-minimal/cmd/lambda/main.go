@@ -31,7 +31,10 @@ func init() {
 	}
 
 	// Create Skyflow client
-	client = skyflow.NewClient(cfg)
+	client, err = skyflow.NewClient(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create Skyflow client: %v", err)
+	}
 
 	log.Printf("Initialized with vault URL: %s", cfg.VaultURL)
 	log.Printf("Batch size: %d, Max concurrency: %d", cfg.BatchSize, cfg.MaxConcurrency)
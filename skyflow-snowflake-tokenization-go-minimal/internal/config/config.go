@@ -110,6 +110,16 @@ func loadFromEnvironment() (*types.Config, error) {
 				Column:  getEnv("COLUMN_SSN", "ssn"),
 			},
 		},
+		Network: types.NetworkConfig{
+			ProxyURL:                getEnv("PROXY_URL", ""),
+			CABundle:                getEnv("CA_BUNDLE", ""),
+			ClientCert:              getEnv("CLIENT_CERT", ""),
+			ClientKey:               getEnv("CLIENT_KEY", ""),
+			MinTLSVersion:           getEnv("MIN_TLS_VERSION", ""),
+			DialTimeoutMs:           getEnvInt("DIAL_TIMEOUT_MS", 0),
+			TLSHandshakeTimeoutMs:   getEnvInt("TLS_HANDSHAKE_TIMEOUT_MS", 0),
+			ResponseHeaderTimeoutMs: getEnvInt("RESPONSE_HEADER_TIMEOUT_MS", 0),
+		},
 	}
 
 	// Validate required fields
@@ -3,11 +3,15 @@ package skyflow
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"sync"
 	"time"
 
@@ -27,37 +31,22 @@ type Client struct {
 }
 
 // NewClient creates a new Skyflow client
-func NewClient(config *types.Config) *Client {
+func NewClient(config *types.Config) (*Client, error) {
 	// Performance Optimization #1: HTTP Transport Configuration
 	// - HTTP/2 enabled for connection multiplexing
 	// - Increased connection pool sizes for high concurrency
 	// - Optimized timeouts for Lambda environment
+	transport, err := newTransport(config.Network)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &Client{
 		vaultURL:    config.VaultURL,
 		bearerToken: config.BearerToken,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        200, // Increased from default 100
-				MaxIdleConnsPerHost: 50,  // 2-3x max_concurrency for bursts
-				MaxConnsPerHost:     50,  // Prevent connection starvation
-				IdleConnTimeout:     120 * time.Second,
-				DisableKeepAlives:   false,
-				ForceAttemptHTTP2:   true, // Enable HTTP/2 for multiplexing
-
-				// Optimize TCP connection establishment
-				DialContext: (&net.Dialer{
-					Timeout:   10 * time.Second,
-					KeepAlive: 60 * time.Second,
-				}).DialContext,
-
-				// TLS optimization
-				TLSHandshakeTimeout: 10 * time.Second,
-
-				// Response optimization
-				ResponseHeaderTimeout: 20 * time.Second,
-				ExpectContinueTimeout: 1 * time.Second,
-			},
+			Timeout:   60 * time.Second,
+			Transport: transport,
 		},
 		retrier:        NewRetrier(config.MaxRetries, config.RetryDelayMs),
 		batchSize:      config.BatchSize,
@@ -76,7 +65,99 @@ func NewClient(config *types.Config) *Client {
 	// Fixed pool of goroutines eliminates goroutine creation overhead
 	client.workerPool = NewWorkerPool(config.MaxConcurrency)
 
-	return client
+	return client, nil
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTransport builds the pooled HTTP/2 transport with the configured proxy, TLS trust,
+// client certificate and timeouts
+func newTransport(nc types.NetworkConfig) (*http.Transport, error) {
+	dialTimeout := 10 * time.Second
+	if nc.DialTimeoutMs > 0 {
+		dialTimeout = time.Duration(nc.DialTimeoutMs) * time.Millisecond
+	}
+	handshakeTimeout := 10 * time.Second
+	if nc.TLSHandshakeTimeoutMs > 0 {
+		handshakeTimeout = time.Duration(nc.TLSHandshakeTimeoutMs) * time.Millisecond
+	}
+	headerTimeout := 20 * time.Second
+	if nc.ResponseHeaderTimeoutMs > 0 {
+		headerTimeout = time.Duration(nc.ResponseHeaderTimeoutMs) * time.Millisecond
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        200, // Increased from default 100
+		MaxIdleConnsPerHost: 50,  // 2-3x max_concurrency for bursts
+		MaxConnsPerHost:     50,  // Prevent connection starvation
+		IdleConnTimeout:     120 * time.Second,
+		DisableKeepAlives:   false,
+		ForceAttemptHTTP2:   true, // Enable HTTP/2 for multiplexing
+		Proxy:               http.ProxyFromEnvironment,
+
+		// Optimize TCP connection establishment
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 60 * time.Second,
+		}).DialContext,
+
+		// TLS optimization
+		TLSHandshakeTimeout: handshakeTimeout,
+
+		// Response optimization
+		ResponseHeaderTimeout: headerTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if nc.ProxyURL != "" {
+		proxyURL, err := url.Parse(nc.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url %q", nc.ProxyURL)
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+			return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if nc.MinTLSVersion != "" {
+		version, ok := tlsVersions[nc.MinTLSVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported min_tls_version %q (use 1.2 or 1.3)", nc.MinTLSVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	if nc.CABundle != "" {
+		pemData, err := os.ReadFile(nc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", nc.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if nc.ClientCert != "" || nc.ClientKey != "" {
+		if nc.ClientCert == "" || nc.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(nc.ClientCert, nc.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
 }
 
 // WorkerPool manages a fixed pool of worker goroutines
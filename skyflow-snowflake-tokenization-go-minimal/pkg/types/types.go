@@ -31,6 +31,21 @@ type Config struct {
 
 	// Data type mappings
 	DataTypeMappings map[DataType]VaultMapping
+
+	// Network configuration (proxy, TLS trust, client certificates, timeouts)
+	Network NetworkConfig `json:"network"`
+}
+
+// NetworkConfig controls how Skyflow is reached; same options as the loader's "network" section
+type NetworkConfig struct {
+	ProxyURL                string `json:"proxy_url"`                  // http(s):// or socks5:// proxy (default: HTTPS_PROXY/NO_PROXY environment)
+	CABundle                string `json:"ca_bundle"`                  // PEM file of extra root CAs, added to the system roots
+	ClientCert              string `json:"client_cert"`                // PEM client certificate for mutual TLS
+	ClientKey               string `json:"client_key"`                 // PEM private key for client_cert
+	MinTLSVersion           string `json:"min_tls_version"`            // "1.2" (default) or "1.3"
+	DialTimeoutMs           int    `json:"dial_timeout_ms"`            // TCP connect timeout (default 10000)
+	TLSHandshakeTimeoutMs   int    `json:"tls_handshake_timeout_ms"`   // TLS handshake timeout (default 10000)
+	ResponseHeaderTimeoutMs int    `json:"response_header_timeout_ms"` // Wait for response headers (default 20000)
 }
 
 // VaultMapping defines vault, table, and column for a data type